@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+
+	"github.com/danmrichards/go-invaders/internal/config"
+	"github.com/danmrichards/go-invaders/internal/sound"
+)
+
+// newMixer returns a mixer with the configured settings, of a new instance of
+// the configured sound engine: the recorded samples, from the configured
+// sample pack if there is one, or the synthesiser.
+func newMixer(cfg *config.Config) (*sound.Mixer, error) {
+	var src sound.Source
+	switch cfg.Sound {
+	case "synth":
+		src = sound.NewSynth()
+	default:
+		p, err := sound.NewPlayer(sound.WithSamplePack(cfg.Samples))
+		if err != nil {
+			return nil, err
+		}
+		src = p
+	}
+
+	return sound.NewMixer(src, cfg.Mixer)
+}
+
+// saveMixer saves the settings of the mixer to the configuration file, if they
+// were changed from the given settings while the machine ran.
+//
+// Only the mixer settings of the file are replaced, so that settings given on
+// the command line are not saved.
+func saveMixer(mx *sound.Mixer, was sound.MixerSettings) error {
+	s := mx.Settings()
+	if reflect.DeepEqual(s, was) {
+		return nil
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+	cfg.Mixer = s
+
+	return cfg.Save(configPath)
+}
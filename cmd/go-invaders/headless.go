@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/danmrichards/go-invaders/internal/config"
+	"github.com/danmrichards/go-invaders/internal/machine"
+	"github.com/danmrichards/go-invaders/internal/memory"
+	"github.com/danmrichards/go-invaders/internal/sound"
+)
+
+// runHeadless runs the Space Invaders machine without a window or audio
+// device, then writes the final frame and RAM dump, and the sound if
+// requested, to disk.
+func runHeadless(mem *memory.Mapped, cfg *config.Config) error {
+	stop, err := parseUntil(mem, until)
+	if err != nil {
+		return err
+	}
+	if frames == 0 && stop == nil {
+		return errors.New("headless mode requires -frames or -until")
+	}
+
+	opts := []machine.Option{
+		machine.WithDIP(cfg.DIP),
+	}
+	if debug {
+		opts = append(opts, machine.WithDebugEnabled())
+	}
+
+	// The sound is rendered on the emulated timeline, if it was requested.
+	var wav *sound.WAV
+	if wavPath != "" {
+		mx, err := newMixer(cfg)
+		if err != nil {
+			return err
+		}
+		if wav, err = sound.NewWAV(wavPath, mx); err != nil {
+			return fmt.Errorf("write sound: %w", err)
+		}
+		opts = append(opts, machine.WithAudio(wav))
+	}
+
+	m, err := machine.New(mem, opts...)
+	if err != nil {
+		return err
+	}
+
+	endMovie, err := startMovie(m)
+	if err != nil {
+		return fmt.Errorf("start movie: %w", err)
+	}
+
+	if err = startVideo(m, cfg); err != nil {
+		return fmt.Errorf("record video: %w", err)
+	}
+
+	n, err := m.RunFrames(frames, stop)
+	if err != nil {
+		return err
+	}
+	if err = m.StopRecording(); err != nil {
+		return fmt.Errorf("record video: %w", err)
+	}
+	if wav != nil {
+		if err = wav.Close(); err != nil {
+			return fmt.Errorf("write sound: %w", err)
+		}
+	}
+	log.Printf("emulated %d frames", n)
+	if w := mem.ROMWrites(); w > 0 {
+		log.Printf("ignored %d writes to the ROM", w)
+	}
+
+	f, desync := m.Desync()
+	if err = endMovie(); err != nil {
+		return fmt.Errorf("stop movie: %w", err)
+	}
+
+	if err = writeFile(pngPath, m.WritePNG); err != nil {
+		return fmt.Errorf("write frame: %w", err)
+	}
+	if err = writeFile(ramPath, m.DumpRAM); err != nil {
+		return fmt.Errorf("write RAM dump: %w", err)
+	}
+
+	if desync {
+		return fmt.Errorf("movie desync at frame %d", f)
+	}
+
+	return nil
+}
+
+// parseUntil parses a stop condition of the form ADDR=VALUE, which is met when
+// the memory at ADDR holds VALUE. An empty condition returns a nil function.
+func parseUntil(mem *memory.Mapped, cond string) (func() bool, error) {
+	if cond == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(cond, "=", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid condition %q: expected ADDR=VALUE", cond)
+	}
+
+	addr, err := strconv.ParseUint(parts[0], 0, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition address %q: %w", parts[0], err)
+	}
+	v, err := strconv.ParseUint(parts[1], 0, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition value %q: %w", parts[1], err)
+	}
+
+	return func() bool {
+		return mem.Read(uint16(addr)) == byte(v)
+	}, nil
+}
+
+// writeFile creates the file at path and writes to it with fn.
+func writeFile(path string, fn func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
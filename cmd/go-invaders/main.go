@@ -3,61 +3,306 @@ package main
 import (
 	"flag"
 	"fmt"
+	"image"
 	"log"
+	"os"
+	"strconv"
+	"strings"
 
+	"github.com/danmrichards/go-invaders/internal/config"
+	"github.com/danmrichards/go-invaders/internal/keymap"
 	"github.com/danmrichards/go-invaders/internal/machine"
 	"github.com/danmrichards/go-invaders/internal/memory"
+	"github.com/danmrichards/go-invaders/internal/overlay"
+	"github.com/danmrichards/go-invaders/internal/sound"
+	"github.com/danmrichards/go-invaders/internal/sound/speaker"
+	"github.com/danmrichards/go-invaders/internal/video"
+	"github.com/danmrichards/go-invaders/internal/window"
 	"github.com/faiface/pixel/pixelgl"
 )
 
 var (
-	dir         string
-	debug       bool
-	scaleFactor int
+	romPath      string
+	debug        bool
+	scaleFactor  int
+	headless     bool
+	frames       int
+	until        string
+	pngPath      string
+	ramPath      string
+	wavPath      string
+	stateDir     string
+	rewind       int
+	rewindEvery  int
+	recordPath   string
+	playPath     string
+	logROMWrites bool
+	configPath   string
+	lives        int
+	bonusLife    int
+	coinInfo     bool
+	deadZone     float64
+	overlayName  string
+	filters      string
+	shotDir      string
+	shotRaw      bool
+	shotScale    int
+	videoPath    string
+	videoDir     string
+	videoFormat  string
+	videoScale   int
+	soundEngine  string
+	samplePack   string
+	syncMode     string
+	breakAt      string
 )
 
 func main() {
-	flag.StringVar(&dir, "dir", "roms", "Path to directory containing ROM files")
+	flag.StringVar(&romPath, "rom", "roms", "Path to a directory of ROM files, a MAME zip archive or a single 8K ROM image")
+	flag.StringVar(&romPath, "dir", "roms", "Deprecated: use -rom")
 	flag.BoolVar(&debug, "debug", false, "Run the emulator in debug mode")
-	flag.IntVar(&scaleFactor, "scale-factor", 2, "Scales the original video resolution (224x256)")
-	flag.Parse()
+	flag.StringVar(&breakAt, "break", "", "Comma separated addresses to break at, opening the debug monitor (e.g. 0x18dc,0x0a93)")
+	flag.StringVar(&configPath, "config", config.DefaultPath(), "Path to the configuration file")
+	flag.IntVar(&lives, "lives", 3, "DIP switch: number of lives per game (3-6)")
+	flag.IntVar(&bonusLife, "bonus-life", 1500, "DIP switch: score at which a bonus life is awarded (1000 or 1500)")
+	flag.BoolVar(&coinInfo, "coin-info", true, "DIP switch: show the coin info on the demo screen")
+	flag.Float64Var(&deadZone, "dead-zone", 0.25, "Gamepad axis values closer to the centre than this (0-1) are ignored")
+	flag.StringVar(&soundEngine, "sound", "samples", "Sound engine: samples to play recorded samples, or synth to synthesise the sound board")
+	flag.StringVar(&samplePack, "samples", "", "Path to a directory or zip archive of samples (e.g. MAME's samples/invaders.zip) replacing the built in samples")
+	flag.StringVar(&syncMode, "sync", "time", "Emulation sync: time to pace by wall time, or audio to pace by the audio device")
+	flag.StringVar(&stateDir, "state-dir", "states", "Path to directory to store save states in")
+	flag.StringVar(&shotDir, "screenshot-dir", "screenshots", "Path to directory to store screenshots in")
+	flag.BoolVar(&shotRaw, "screenshot-raw", false, "Take screenshots of the raw 1bpp screen, without the overlay or scaling")
+	flag.IntVar(&shotScale, "screenshot-scale", 1, "Scales screenshots of the original video resolution (224x256)")
+	flag.IntVar(&rewind, "rewind", 120, "Seconds of gameplay history to keep for rewinding (0 = disabled)")
+	flag.IntVar(&rewindEvery, "rewind-interval", 2, "Number of frames between rewind snapshots")
+	flag.StringVar(&recordPath, "record", "", "Path to record a movie of the input to")
+	flag.StringVar(&videoPath, "record-video", "", "Path to record a video to from start up, either a .gif or a .y4m (with a .wav alongside)")
+	flag.StringVar(&videoDir, "video-dir", "recordings", "Path to directory to store videos recorded with the record hotkey in")
+	flag.StringVar(&videoFormat, "video-format", "gif", "Format of videos recorded with the record hotkey: gif or y4m")
+	flag.IntVar(&videoScale, "video-scale", 1, "Scales recorded videos of the original video resolution (224x256)")
+	flag.StringVar(&playPath, "play", "", "Path to a movie to play back in place of the keyboard")
+	flag.BoolVar(&logROMWrites, "log-rom-writes", false, "Log attempted writes to the ROM")
+	flag.StringVar(&overlayName, "overlay", "mono", "Colour overlay: "+strings.Join(overlay.Presets(), ", ")+" or the path to an overlay file")
+	flag.StringVar(&filters, "filters", "nearest:2", "Comma separated post-processing filters: scale2x, scale3x, nearest:FACTOR, bilinear:FACTOR, scanlines:INTENSITY, phosphor:PERSISTENCE")
+	flag.IntVar(&scaleFactor, "scale-factor", 2, "Deprecated: use -filters nearest:N")
+	flag.BoolVar(&headless, "headless", false, "Run the emulator without a window or audio device")
+	flag.IntVar(&frames, "frames", 0, "Number of frames to emulate in headless mode (0 = until the -until condition is met)")
+	flag.StringVar(&until, "until", "", "Stop headless mode once the memory condition ADDR=VALUE is met (e.g. 0x20ef=0x01)")
+	flag.StringVar(&pngPath, "png", "frame.png", "Path to write the final frame to in headless mode")
+	flag.StringVar(&ramPath, "ram", "ram.bin", "Path to write the final RAM dump to in headless mode")
+	flag.StringVar(&wavPath, "wav", "", "Path to write the sound to in headless mode, as a WAV file")
 
-	// TODO: Implement configuration for colours.
+	// The run command is the default command, so it may be omitted.
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "run" {
+		args = args[1:]
+	}
+	flag.CommandLine.Parse(args) //nolint:errcheck
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Instantiate the memory, with the address decoding of the original
+	// board.
+	var mopts []memory.MappedOption
+	if logROMWrites {
+		mopts = append(mopts, memory.WithROMWriteLogging())
+	}
+	mem := memory.NewMapped(mopts...)
+	rr, err := mem.LoadROM(romPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Print(rr)
+
+	if headless {
+		if err = runHeadless(mem, cfg); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// The sound is mixed and played through the speaker, or discarded if
+	// there is no audio device.
+	mx, err := newMixer(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var a machine.Audio
+	if a, err = speaker.New(mx); err != nil {
+		log.Printf("sound disabled: %v", err)
+		a = sound.Null{}
+	}
+
+	kb, err := keymap.Parse(cfg.Keys)
+	if err != nil {
+		log.Fatalf("invalid config (%q): %v", configPath, err)
+	}
+	ov, err := overlay.Load(cfg.Overlay)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fp, err := video.ParseFilters(cfg.Filters)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(kb.Help())
+
+	// Screenshots are taken through the overlay, unless raw screenshots
+	// were requested.
+	var shot func(machine.Screen) image.Image
+	if !shotRaw {
+		shot = func(s machine.Screen) image.Image {
+			return video.Screenshot(s.VRAM(), ov, shotScale)
+		}
+	}
+
+	wopts := []window.Option{
+		window.WithBindings(kb),
+		window.WithGamepads(cfg.Gamepads),
+		window.WithOverlay(ov),
+		window.WithFilters(fp),
+	}
+
+	pixelgl.Run(func() {
+		run(mem, cfg, wopts, shot, a, mx)
+	})
+}
 
-	// Instantiate 64K of memory.
-	mem := make(memory.Basic, 65536)
-	if err := mem.LoadROM(dir); err != nil {
+// loadConfig returns the configuration loaded from the configuration file,
+// overridden by any command line flags that were set.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+
+		switch f.Name {
+		case "lives":
+			cfg.DIP.Lives = lives
+		case "bonus-life":
+			cfg.DIP.BonusLife = bonusLife
+		case "coin-info":
+			cfg.DIP.CoinInfo = coinInfo
+		case "overlay":
+			cfg.Overlay = overlayName
+		case "filters":
+			cfg.Filters = filters
+		case "sound":
+			cfg.Sound = soundEngine
+		case "samples":
+			cfg.Samples = samplePack
+		case "sync":
+			cfg.Sync = syncMode
+		case "dead-zone":
+			for i := range cfg.Gamepads {
+				cfg.Gamepads[i].DeadZone = deadZone
+			}
+		}
+	})
+
+	// The deprecated scale factor is equivalent to nearest neighbour scaling.
+	if set["scale-factor"] && !set["filters"] {
+		cfg.Filters = fmt.Sprintf("nearest:%d", scaleFactor)
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// run creates the window and runs the Space Invaders machine inside it.
+func run(mem *memory.Mapped, cfg *config.Config, wopts []window.Option, shot func(machine.Screen) image.Image, a machine.Audio, mx *sound.Mixer) {
+	bps, err := parseBreakpoints(breakAt)
+	if err != nil {
 		log.Fatal(err)
 	}
 
+	w, err := window.New(wopts...)
+	if err != nil {
+		log.Fatalf("create window: %v", err)
+	}
+
 	opts := []machine.Option{
-		machine.WithScaleFactor(scaleFactor),
+		machine.WithVideo(w),
+		machine.WithInput(w),
+		machine.WithAudio(a),
+		machine.WithMixer(mx),
+		machine.WithStateDir(stateDir),
+		machine.WithScreenshots(shotDir, shot),
+		machine.WithVideoRecorder(videoRecorder(cfg)),
+		machine.WithDIP(cfg.DIP),
+		machine.WithBreakpoints(bps...),
+	}
+	if rewind > 0 && rewindEvery > 0 {
+		// The machine emulates roughly 60 frames per second.
+		opts = append(opts, machine.WithRewind(rewindEvery, rewind*60/rewindEvery))
 	}
 	if debug {
 		opts = append(opts, machine.WithDebugEnabled())
 	}
 
+	// In audio sync mode emulation is paced by the speaker, which needs an
+	// audio device.
+	if cfg.Sync == "audio" {
+		if spk, ok := a.(*speaker.Speaker); ok {
+			opts = append(opts, machine.WithClock(spk))
+		} else {
+			log.Print("audio sync disabled: no audio device")
+		}
+	}
+
 	// Instantiate the Space Invaders machine.
 	m, err := machine.New(mem, opts...)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Println("*****************************")
-	fmt.Println("* Welcome to Space Invaders *")
-	fmt.Println("*                           *")
-	fmt.Println("* Insert coin = C           *")
-	fmt.Println("* 1P start = 1              *")
-	fmt.Println("* 2P start = 2              *")
-	fmt.Println("* 1P shoot = W              *")
-	fmt.Println("* 1P left = Q               *")
-	fmt.Println("* 1P right = E              *")
-	fmt.Println("* 2P shoot = O              *")
-	fmt.Println("* 2P left = I               *")
-	fmt.Println("* 2P right = P              *")
-	fmt.Println("* Tilt = T                  *")
-	fmt.Println("*                           *")
-	fmt.Println("*****************************")
+	stop, err := startMovie(m)
+	if err != nil {
+		log.Fatalf("start movie: %v", err)
+	}
+
+	if err = startVideo(m, cfg); err != nil {
+		log.Fatalf("record video: %v", err)
+	}
+
+	mixed := mx.Settings()
+	if err = m.Run(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err = m.StopRecording(); err != nil {
+		log.Fatalf("record video: %v", err)
+	}
+	if err = stop(); err != nil {
+		log.Fatalf("stop movie: %v", err)
+	}
+	if err = saveMixer(mx, mixed); err != nil {
+		log.Fatalf("save mixer settings: %v", err)
+	}
+}
+
+// parseBreakpoints parses a comma separated list of breakpoint addresses. An
+// empty list returns no breakpoints.
+func parseBreakpoints(list string) ([]uint16, error) {
+	if list == "" {
+		return nil, nil
+	}
+
+	var bps []uint16
+	for _, s := range strings.Split(list, ",") {
+		a, err := strconv.ParseUint(strings.TrimSpace(s), 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid breakpoint address %q: %w", s, err)
+		}
+		bps = append(bps, uint16(a))
+	}
 
-	pixelgl.Run(m.Run)
+	return bps, nil
 }
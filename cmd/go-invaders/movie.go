@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/danmrichards/go-invaders/internal/machine"
+)
+
+// startMovie starts recording or playing back a movie on the machine, as set
+// by the command line flags.
+//
+// The returned function stops the movie and closes the movie file.
+func startMovie(m *machine.Machine) (func() error, error) {
+	switch {
+	case recordPath != "" && playPath != "":
+		return nil, errors.New("cannot both record and play back a movie")
+	case recordPath != "":
+		f, err := os.Create(recordPath)
+		if err != nil {
+			return nil, err
+		}
+		if err = m.RecordMovie(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		return stopMovie(m, f), nil
+	case playPath != "":
+		f, err := os.Open(playPath)
+		if err != nil {
+			return nil, err
+		}
+		if err = m.PlayMovie(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		return stopMovie(m, f), nil
+	}
+
+	return func() error { return nil }, nil
+}
+
+// stopMovie returns a function that stops the movie on the machine and closes
+// the movie file f.
+func stopMovie(m *machine.Machine, f *os.File) func() error {
+	return func() error {
+		if err := m.StopMovie(); err != nil {
+			f.Close()
+			return err
+		}
+
+		return f.Close()
+	}
+}
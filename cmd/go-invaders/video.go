@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/danmrichards/go-invaders/internal/config"
+	"github.com/danmrichards/go-invaders/internal/machine"
+	"github.com/danmrichards/go-invaders/internal/overlay"
+	"github.com/danmrichards/go-invaders/internal/record"
+)
+
+// newVideoRecorder returns a recorder that records video to the file at path,
+// through the configured overlay and scaled by -video-scale.
+func newVideoRecorder(path string, cfg *config.Config) (machine.Recorder, error) {
+	ov, err := overlay.Load(cfg.Overlay)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []record.Option{
+		record.WithOverlay(ov),
+		record.WithScale(videoScale),
+	}
+
+	// Only build a mixer, which loads the samples, for a format with sound.
+	if record.HasSound(path) {
+		mx, err := newMixer(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, record.WithSound(mx))
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	return record.New(path, opts...)
+}
+
+// videoRecorder returns a function that starts a new video recording, in a
+// timestamped file in the -video-dir directory, as done by the record hotkey.
+func videoRecorder(cfg *config.Config) func() (machine.Recorder, error) {
+	return func() (machine.Recorder, error) {
+		name := "recording-" + time.Now().Format("20060102-150405.000") + "." + videoFormat
+		return newVideoRecorder(filepath.Join(videoDir, name), cfg)
+	}
+}
+
+// startVideo starts recording video to -record-video, if it is set.
+func startVideo(m *machine.Machine, cfg *config.Config) error {
+	if videoPath == "" {
+		return nil
+	}
+
+	r, err := newVideoRecorder(videoPath, cfg)
+	if err != nil {
+		return err
+	}
+
+	return m.StartRecording(r)
+}
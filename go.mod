@@ -3,6 +3,7 @@ module github.com/danmrichards/go-invaders
 go 1.14
 
 require (
+	github.com/danmrichards/disassemble8080 v1.1.0
 	github.com/danmrichards/go8080 v1.0.0
 	github.com/faiface/beep v1.0.2
 	github.com/faiface/pixel v0.9.0
@@ -14,3 +15,6 @@ require (
 	golang.org/x/sync v0.0.0-20200317015054-43a5402ce75a // indirect
 	golang.org/x/sys v0.0.0-20200523222454-059865788121 // indirect
 )
+
+// The CPU state accessors are not yet in a tagged release of go8080.
+replace github.com/danmrichards/go8080 => ./third_party/go8080
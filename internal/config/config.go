@@ -0,0 +1,205 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/danmrichards/go-invaders/internal/machine"
+	"github.com/danmrichards/go-invaders/internal/sound"
+	"github.com/danmrichards/go-invaders/internal/video"
+)
+
+// The number of players, each of which may use their own gamepad.
+const players = 2
+
+type (
+	// Config is the emulator configuration, stored as a JSON file.
+	Config struct {
+		// The setting of the DIP switches.
+		DIP machine.DIP `json:"dip"`
+
+		// The keys bound to each input, keyed by input name. Inputs that are
+		// not listed keep their default bindings.
+		Keys map[string][]string `json:"keys,omitempty"`
+
+		// The gamepad of each player, player 1 first.
+		Gamepads []Gamepad `json:"gamepads"`
+
+		// The colour overlay: the name of a built in overlay or the path to
+		// an overlay file.
+		Overlay string `json:"overlay"`
+
+		// The post-processing filters applied to the screen, as accepted by
+		// video.ParseFilters.
+		Filters string `json:"filters"`
+
+		// The sound engine: "samples" to play the recorded samples or
+		// "synth" to synthesise the sound board.
+		Sound string `json:"sound"`
+
+		// The path of a directory or zip archive of samples replacing the
+		// embedded samples, empty for the embedded samples only.
+		Samples string `json:"samples,omitempty"`
+
+		// The volumes of the sound mixer.
+		Mixer sound.MixerSettings `json:"mixer"`
+
+		// What paces emulation: "time" for wall time or "audio" for the
+		// sound played by the audio device.
+		Sync string `json:"sync"`
+	}
+
+	// Gamepad is the configuration of a gamepad or joystick used by a
+	// player.
+	//
+	// Buttons and axes are numbered from zero, in the order reported by the
+	// operating system.
+	Gamepad struct {
+		// The joystick number, from 1 to 16, or 0 to disable the gamepad.
+		Joystick int `json:"joystick"`
+
+		// The axes that move left and right, such as the analog stick and
+		// the d-pad of most controllers.
+		Axes []int `json:"axes"`
+
+		// Axis values closer to the centre than this, from 0 to 1, are
+		// ignored.
+		DeadZone float64 `json:"dead_zone"`
+
+		// The buttons bound to each cabinet input. Controllers that report
+		// the d-pad as buttons can bind them to left and right.
+		Left  []int `json:"left"`
+		Right []int `json:"right"`
+		Shoot []int `json:"shoot"`
+		Start []int `json:"start"`
+		Coin  []int `json:"coin"`
+	}
+)
+
+// Default returns the default configuration.
+func Default() *Config {
+	return &Config{
+		DIP: machine.DefaultDIP(),
+		Gamepads: []Gamepad{
+			DefaultGamepad(1),
+			DefaultGamepad(2),
+		},
+		Overlay: "mono",
+		Filters: "nearest:2",
+		Sound:   "samples",
+		Mixer:   sound.DefaultMixerSettings(),
+		Sync:    "time",
+	}
+}
+
+// DefaultGamepad returns the default configuration of the given joystick,
+// which suits an Xbox style controller.
+func DefaultGamepad(joystick int) Gamepad {
+	return Gamepad{
+		Joystick: joystick,
+		Axes:     []int{0, 6},
+		DeadZone: 0.25,
+		Shoot:    []int{0, 1},
+		Start:    []int{7},
+		Coin:     []int{6},
+	}
+}
+
+// DefaultPath returns the default path of the configuration file, within the
+// user's configuration directory.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "go-invaders.json"
+	}
+
+	return filepath.Join(dir, "go-invaders", "config.json")
+}
+
+// Load returns the configuration read from the file at path.
+//
+// Any setting missing from the file takes its default value. If the file does
+// not exist the default configuration is returned.
+func Load(path string) (*Config, error) {
+	c := Default()
+
+	b, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("parse config (%q): %w", path, err)
+	}
+	if err = c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config (%q): %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Validate returns an error if any setting is invalid.
+func (c *Config) Validate() error {
+	for name := range c.Keys {
+		if _, err := machine.ParseButton(name); err != nil {
+			return fmt.Errorf("keys: %w", err)
+		}
+	}
+
+	if len(c.Gamepads) > players {
+		return fmt.Errorf("gamepads: at most %d gamepads may be configured", players)
+	}
+	for i, gp := range c.Gamepads {
+		if err := gp.Validate(); err != nil {
+			return fmt.Errorf("gamepad %d: %w", i+1, err)
+		}
+	}
+
+	if _, err := video.ParseFilters(c.Filters); err != nil {
+		return fmt.Errorf("filters: %w", err)
+	}
+	if c.Sound != "samples" && c.Sound != "synth" {
+		return fmt.Errorf("invalid sound engine %q: must be samples or synth", c.Sound)
+	}
+	if err := c.Mixer.Validate(); err != nil {
+		return fmt.Errorf("mixer: %w", err)
+	}
+	if c.Sync != "time" && c.Sync != "audio" {
+		return fmt.Errorf("invalid sync mode %q: must be time or audio", c.Sync)
+	}
+
+	return c.DIP.Validate()
+}
+
+// Validate returns an error if any setting is invalid.
+func (gp Gamepad) Validate() error {
+	if gp.Joystick < 0 || gp.Joystick > 16 {
+		return fmt.Errorf("invalid joystick %d: must be 1 to 16, or 0 to disable", gp.Joystick)
+	}
+	if gp.DeadZone < 0 || gp.DeadZone >= 1 {
+		return fmt.Errorf("invalid dead zone %g: must be at least 0 and less than 1", gp.DeadZone)
+	}
+
+	return nil
+}
+
+// Save writes the configuration to the file at path.
+func (c *Config) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(b, '\n'), 0o644)
+}
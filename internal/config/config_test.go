@@ -0,0 +1,150 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/danmrichards/go-invaders/internal/machine"
+)
+
+// writeConfig writes a configuration file, removed when the test ends, and
+// returns its path.
+func writeConfig(t *testing.T, json string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "config.json")
+	if err = ioutil.WriteFile(path, []byte(json), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		edit func(c *Config)
+	}{
+		{"empty", `{}`, func(c *Config) {}},
+		{
+			name: "partial",
+			json: `{"dip": {"lives": 5}, "sound": "synth", "mixer": {"volume": 0.5}}`,
+			edit: func(c *Config) {
+				c.DIP.Lives = 5
+				c.Sound = "synth"
+				c.Mixer.Volume = 0.5
+			},
+		},
+		{
+			name: "keys",
+			json: `{"keys": {"p1-shoot": ["Z", "Space"], "rewind": []}}`,
+			edit: func(c *Config) {
+				c.Keys = map[string][]string{"p1-shoot": {"Z", "Space"}, "rewind": {}}
+			},
+		},
+		{
+			// The settings missing from a gamepad keep those of the default
+			// gamepad of the player.
+			name: "one gamepad",
+			json: `{"gamepads": [{"joystick": 3, "dead_zone": 0.5}]}`,
+			edit: func(c *Config) {
+				gp := DefaultGamepad(3)
+				gp.DeadZone = 0.5
+				c.Gamepads = []Gamepad{gp}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(writeConfig(t, tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := Default()
+			tt.edit(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("config = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	c, err := Load(filepath.Join(os.TempDir(), "no-such-dir", "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, Default()) {
+		t.Errorf("config = %+v, want the default", c)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"not json", `{"dip":`},
+		{"wrong type", `{"sound": 1}`},
+		{"unknown input", `{"keys": {"p3-shoot": ["X"]}}`},
+		{"too many gamepads", `{"gamepads": [{}, {}, {}]}`},
+		{"negative joystick", `{"gamepads": [{"joystick": -1}]}`},
+		{"joystick too high", `{"gamepads": [{"joystick": 17}]}`},
+		{"negative dead zone", `{"gamepads": [{"dead_zone": -0.1}]}`},
+		{"dead zone too high", `{"gamepads": [{"dead_zone": 1}]}`},
+		{"unknown filter", `{"filters": "blur"}`},
+		{"unknown sound engine", `{"sound": "beeper"}`},
+		{"invalid mixer", `{"mixer": {"volume": 3}}`},
+		{"unknown mixer channel", `{"mixer": {"channels": {"laser": 1}}}`},
+		{"unknown sync mode", `{"sync": "vsync"}`},
+		{"invalid lives", `{"dip": {"lives": 7}}`},
+		{"invalid bonus life", `{"dip": {"bonus_life": 2000}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(writeConfig(t, tt.json)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := Default()
+	c.DIP = machine.DIP{Lives: 6, BonusLife: 1000}
+	c.Keys = map[string][]string{"coin": {"C"}}
+	c.Gamepads = c.Gamepads[:1]
+	c.Overlay = "upright"
+	c.Sync = "audio"
+
+	// The directory of the file is created.
+	path := filepath.Join(dir, "go-invaders", "config.json")
+	if err = c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Errorf("loaded %+v, want %+v", got, c)
+	}
+}
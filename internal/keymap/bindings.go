@@ -0,0 +1,144 @@
+// Package keymap maps the keyboard keys and mouse buttons of the window to the
+// logical inputs of the machine.
+package keymap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danmrichards/go-invaders/internal/machine"
+)
+
+// Bindings maps the logical cabinet inputs and emulator hotkeys to one or more
+// keyboard keys or mouse buttons.
+type Bindings map[machine.Button][]Key
+
+// defaultKeys are the names of the keys bound to each input by default.
+var defaultKeys = map[machine.Button][]string{
+	machine.ButtonCoin:    {"C"},
+	machine.ButtonP1Start: {"1"},
+	machine.ButtonP2Start: {"2"},
+	machine.ButtonP1Shoot: {"W"},
+	machine.ButtonP1Left:  {"Q"},
+	machine.ButtonP1Right: {"E"},
+	machine.ButtonP2Shoot: {"O"},
+	machine.ButtonP2Left:  {"I"},
+	machine.ButtonP2Right: {"P"},
+	machine.ButtonTilt:    {"T"},
+
+	machine.ButtonSaveState: {"F5"},
+	machine.ButtonPrevSlot:  {"F6"},
+	machine.ButtonNextSlot:  {"F7"},
+	machine.ButtonLoadState: {"F8"},
+	machine.ButtonRewind:    {"Backspace"},
+
+	machine.ButtonPause:        {"Space"},
+	machine.ButtonFrameAdvance: {"Period"},
+	machine.ButtonSpeedDown:    {"Minus"},
+	machine.ButtonSpeedUp:      {"Equal"},
+	machine.ButtonSpeedReset:   {"0"},
+	machine.ButtonDIPMenu:      {"F2"},
+	machine.ButtonScreenshot:   {"F12"},
+	machine.ButtonRecordVideo:  {"F9"},
+
+	machine.ButtonVolumeDown: {"LeftBracket"},
+	machine.ButtonVolumeUp:   {"RightBracket"},
+	machine.ButtonMute:       {"M"},
+	machine.ButtonMixerMenu:  {"F3"},
+
+	machine.ButtonMonitor: {"F4"},
+}
+
+// Default returns the default key bindings.
+func Default() Bindings {
+	b := make(Bindings, len(defaultKeys))
+	for mb, names := range defaultKeys {
+		for _, name := range names {
+			k, err := ParseKey(name)
+			if err != nil {
+				panic(err)
+			}
+			b[mb] = append(b[mb], k)
+		}
+	}
+
+	return b
+}
+
+// Parse returns the default key bindings, with the bindings of any input named
+// in keys replaced by the named keys.
+//
+// Inputs are named as by machine.Button.String and keys as by Key.String,
+// ignoring case (e.g. "p1-shoot": ["W", "LeftControl"]). An input bound to no
+// keys is disabled.
+func Parse(keys map[string][]string) (Bindings, error) {
+	b := Default()
+	for name, kk := range keys {
+		mb, err := machine.ParseButton(name)
+		if err != nil {
+			return nil, err
+		}
+
+		pb := make([]Key, 0, len(kk))
+		for _, k := range kk {
+			p, err := ParseKey(k)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			pb = append(pb, p)
+		}
+		b[mb] = pb
+	}
+
+	return b, nil
+}
+
+// Keys returns the names of the keys bound to the given input.
+func (b Bindings) Keys(mb machine.Button) []string {
+	names := make([]string, 0, len(b[mb]))
+	for _, k := range b[mb] {
+		names = append(names, k.String())
+	}
+
+	return names
+}
+
+// Help returns a help banner describing the given bindings.
+func (b Bindings) Help() string {
+	const title = "Welcome to Space Invaders"
+
+	lines := []string{title, ""}
+	for _, mb := range machine.Buttons() {
+		if mb == machine.ButtonSaveState {
+			lines = append(lines, "")
+		}
+
+		keys := b.Keys(mb)
+		if len(keys) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s = %s", mb.Description(), strings.Join(keys, "/")))
+	}
+	lines = append(lines, "")
+
+	width := 0
+	for _, l := range lines {
+		if len(l) > width {
+			width = len(l)
+		}
+	}
+
+	var sb strings.Builder
+	border := strings.Repeat("*", width+4)
+	sb.WriteString(border + "\n")
+	for _, l := range lines {
+		if l == title {
+			pad := width - len(l)
+			l = strings.Repeat(" ", pad/2) + l + strings.Repeat(" ", pad-pad/2)
+		}
+		fmt.Fprintf(&sb, "* %-*s *\n", width, l)
+	}
+	sb.WriteString(border + "\n")
+
+	return sb.String()
+}
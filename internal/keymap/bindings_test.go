@@ -0,0 +1,111 @@
+package keymap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/danmrichards/go-invaders/internal/machine"
+)
+
+func TestDefault(t *testing.T) {
+	b := Default()
+
+	// Every input is bound, and no key is bound to two inputs.
+	bound := make(map[Key]machine.Button)
+	for _, mb := range machine.Buttons() {
+		if len(b[mb]) == 0 {
+			t.Errorf("%s is not bound", mb)
+		}
+		for _, k := range b[mb] {
+			if o, ok := bound[k]; ok {
+				t.Errorf("%s is bound to both %s and %s", k, o, mb)
+			}
+			bound[k] = mb
+		}
+	}
+
+	if got, want := b.Keys(machine.ButtonP1Shoot), []string{"W"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("p1-shoot keys = %v, want %v", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	b, err := Parse(map[string][]string{
+		"p1-shoot": {"z", "LeftControl"},
+		"rewind":   {},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Inputs that are not named keep their default bindings.
+	want := Default()
+	want[machine.ButtonP1Shoot] = []Key{90, 341}
+	want[machine.ButtonRewind] = []Key{}
+	if !reflect.DeepEqual(b, want) {
+		t.Errorf("bindings = %v, want %v", b, want)
+	}
+
+	if b, err = Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, Default()) {
+		t.Errorf("bindings = %v, want the default", b)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		keys map[string][]string
+		err  string
+	}{
+		{"unknown input", map[string][]string{"p3-shoot": {"X"}}, `unknown input "p3-shoot"`},
+		{"input case", map[string][]string{"P1-Shoot": {"X"}}, `unknown input "P1-Shoot"`},
+		{"unknown key", map[string][]string{"p1-shoot": {"W", "Ctrl"}}, `p1-shoot: unknown key "Ctrl"`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.keys)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: error = %v, want %s", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	b := Default()
+	b[machine.ButtonP1Shoot] = []Key{87, 341}
+	b[machine.ButtonRewind] = nil
+
+	help := b.Help()
+	lines := strings.Split(strings.TrimSuffix(help, "\n"), "\n")
+
+	// Every line is boxed to the same width, with the title centred.
+	for i, l := range lines {
+		if len(l) != len(lines[0]) {
+			t.Errorf("line %d is %d wide, want %d: %q", i, len(l), len(lines[0]), l)
+		}
+	}
+	inner := strings.TrimSuffix(strings.TrimPrefix(lines[1], "* "), " *")
+	title := strings.TrimSpace(inner)
+	if title != "Welcome to Space Invaders" {
+		t.Errorf("title = %q", title)
+	}
+	left := len(inner) - len(strings.TrimLeft(inner, " "))
+	right := len(inner) - len(strings.TrimRight(inner, " "))
+	if left != right && left+1 != right {
+		t.Errorf("title is not centred: %q", lines[1])
+	}
+
+	for _, want := range []string{"* 1P shoot = W/LeftControl ", "* Insert coin = C ", "* Pause = Space "} {
+		if !strings.Contains(help, want) {
+			t.Errorf("help does not contain %q:\n%s", want, help)
+		}
+	}
+
+	// A disabled input is left out.
+	if strings.Contains(help, "Rewind") {
+		t.Errorf("help lists the disabled rewind input:\n%s", help)
+	}
+}
@@ -0,0 +1,164 @@
+package keymap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Key is a keyboard key or mouse button, numbered as by GLFW and so by
+// pixelgl.Button, which a window converts it to.
+type Key int
+
+// keyNames are the names of the keys and mouse buttons, as given by
+// pixelgl.Button.String.
+var keyNames = map[Key]string{
+	0:   "MouseButtonLeft",
+	1:   "MouseButtonRight",
+	2:   "MouseButtonMiddle",
+	3:   "MouseButton4",
+	4:   "MouseButton5",
+	5:   "MouseButton6",
+	6:   "MouseButton7",
+	7:   "MouseButton8",
+	32:  "Space",
+	39:  "Apostrophe",
+	44:  "Comma",
+	45:  "Minus",
+	46:  "Period",
+	47:  "Slash",
+	48:  "0",
+	49:  "1",
+	50:  "2",
+	51:  "3",
+	52:  "4",
+	53:  "5",
+	54:  "6",
+	55:  "7",
+	56:  "8",
+	57:  "9",
+	59:  "Semicolon",
+	61:  "Equal",
+	65:  "A",
+	66:  "B",
+	67:  "C",
+	68:  "D",
+	69:  "E",
+	70:  "F",
+	71:  "G",
+	72:  "H",
+	73:  "I",
+	74:  "J",
+	75:  "K",
+	76:  "L",
+	77:  "M",
+	78:  "N",
+	79:  "O",
+	80:  "P",
+	81:  "Q",
+	82:  "R",
+	83:  "S",
+	84:  "T",
+	85:  "U",
+	86:  "V",
+	87:  "W",
+	88:  "X",
+	89:  "Y",
+	90:  "Z",
+	91:  "LeftBracket",
+	92:  "Backslash",
+	93:  "RightBracket",
+	96:  "GraveAccent",
+	161: "World1",
+	162: "World2",
+	256: "Escape",
+	257: "Enter",
+	258: "Tab",
+	259: "Backspace",
+	260: "Insert",
+	261: "Delete",
+	262: "Right",
+	263: "Left",
+	264: "Down",
+	265: "Up",
+	266: "PageUp",
+	267: "PageDown",
+	268: "Home",
+	269: "End",
+	280: "CapsLock",
+	281: "ScrollLock",
+	282: "NumLock",
+	283: "PrintScreen",
+	284: "Pause",
+	290: "F1",
+	291: "F2",
+	292: "F3",
+	293: "F4",
+	294: "F5",
+	295: "F6",
+	296: "F7",
+	297: "F8",
+	298: "F9",
+	299: "F10",
+	300: "F11",
+	301: "F12",
+	302: "F13",
+	303: "F14",
+	304: "F15",
+	305: "F16",
+	306: "F17",
+	307: "F18",
+	308: "F19",
+	309: "F20",
+	310: "F21",
+	311: "F22",
+	312: "F23",
+	313: "F24",
+	314: "F25",
+	320: "KP0",
+	321: "KP1",
+	322: "KP2",
+	323: "KP3",
+	324: "KP4",
+	325: "KP5",
+	326: "KP6",
+	327: "KP7",
+	328: "KP8",
+	329: "KP9",
+	330: "KPDecimal",
+	331: "KPDivide",
+	332: "KPMultiply",
+	333: "KPSubtract",
+	334: "KPAdd",
+	335: "KPEnter",
+	336: "KPEqual",
+	340: "LeftShift",
+	341: "LeftControl",
+	342: "LeftAlt",
+	343: "LeftSuper",
+	344: "RightShift",
+	345: "RightControl",
+	346: "RightAlt",
+	347: "RightSuper",
+	348: "Menu",
+}
+
+// String returns the name of the key.
+func (k Key) String() string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+
+	return "Invalid"
+}
+
+// ParseKey returns the keyboard key or mouse button with the given name,
+// ignoring case.
+func ParseKey(name string) (Key, error) {
+	for k, s := range keyNames {
+		if strings.EqualFold(s, name) {
+			return k, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown key %q", name)
+}
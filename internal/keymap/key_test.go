@@ -0,0 +1,54 @@
+package keymap
+
+import "testing"
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want Key
+	}{
+		{"W", 87},
+		{"w", 87},
+		{"0", 48},
+		{"Space", 32},
+		{"leftcontrol", 341},
+		{"F12", 301},
+		{"KPEnter", 335},
+		{"MouseButtonLeft", 0},
+		{"MouseButton8", 7},
+		{"Menu", 348},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.name)
+		if err != nil {
+			t.Errorf("ParseKey(%q): %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseKey(%q) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	for _, name := range []string{"", "Ctrl", "Unknown", "Invalid", "MouseButton1", "F26"} {
+		if _, err := ParseKey(name); err == nil {
+			t.Errorf("ParseKey(%q): expected an error", name)
+		}
+	}
+}
+
+func TestKeyString(t *testing.T) {
+	for k, name := range keyNames {
+		if got := k.String(); got != name {
+			t.Errorf("Key(%d).String() = %q, want %q", k, got, name)
+		}
+		if got, err := ParseKey(name); err != nil || got != k {
+			t.Errorf("ParseKey(%q) = %d, %v, want %d", name, got, err, k)
+		}
+	}
+
+	for _, k := range []Key{-1, 8, 349} {
+		if got := k.String(); got != "Invalid" {
+			t.Errorf("Key(%d).String() = %q, want Invalid", k, got)
+		}
+	}
+}
@@ -1,5 +1,9 @@
 package machine
 
+import (
+	"fmt"
+)
+
 // stepper is the interface that wraps the basic Step method.
 //
 // Step emulates exactly one instruction on the CPU.
@@ -14,6 +18,14 @@ type interrupter interface {
 	Interrupt(uint16)
 }
 
+// interruptEnabler is the interface that wraps the basic InterruptsEnabled
+// method.
+//
+// InterruptsEnabled returns true if the CPU will accept an interrupt.
+type interruptEnabler interface {
+	InterruptsEnabled() bool
+}
+
 // cycler is the interface that wraps the basic Cycles method.
 //
 // Cycles returns the current cycle count of the CPU.
@@ -28,6 +40,14 @@ type runner interface {
 	Running() bool
 }
 
+// programCounter is the interface that wraps the basic PC method.
+//
+// PC returns the address of the next instruction. Unlike State, it is cheap
+// enough to call before every instruction.
+type programCounter interface {
+	PC() uint16
+}
+
 // accumulator is the interface that wraps the basic Accumulator method.
 //
 // Accumulator returns the current contents of the accumulator.
@@ -35,12 +55,61 @@ type accumulator interface {
 	Accumulator() byte
 }
 
+// stater is the interface that wraps the basic State and SetState methods.
+//
+// State returns a snapshot of the CPU registers, flags and status.
+//
+// SetState restores the CPU registers, flags and status from a snapshot.
+type stater interface {
+	State() cpuState
+	SetState(cpuState)
+}
+
 // processor is the interface that implementations of a CPU are epxected to
 // implement.
 type processor interface {
 	stepper
 	interrupter
+	interruptEnabler
 	cycler
 	runner
+	programCounter
 	accumulator
+	stater
+}
+
+// cpuState is a snapshot of the CPU registers, flags and status.
+type cpuState struct {
+	// Working registers and the accumulator.
+	A, B, C, D, E, H, L byte
+
+	// Condition flags, packed as they are in the program status word.
+	Flags byte
+
+	// Stack pointer and program counter.
+	SP, PC uint16
+
+	// Interrupts enabled and halted status.
+	IE, Halted bool
+
+	// Cycle count.
+	Cycles uint32
+}
+
+// String returns the registers, flags and status, in the form shown by the
+// monitor.
+func (s cpuState) String() string {
+	on := func(b bool) int {
+		if b {
+			return 1
+		}
+		return 0
+	}
+
+	return fmt.Sprintf(
+		"A=%02x B=%02x C=%02x D=%02x E=%02x H=%02x L=%02x SP=%04x PC=%04x\nS=%d Z=%d AC=%d P=%d CY=%d IE=%d HALT=%d",
+		s.A, s.B, s.C, s.D, s.E, s.H, s.L, s.SP, s.PC,
+		on(s.Flags&flagS != 0), on(s.Flags&flagZ != 0), on(s.Flags&flagAC != 0), on(s.Flags&flagP != 0),
+		on(s.Flags&flagCY != 0), on(s.IE), on(s.Halted),
+	)
 }
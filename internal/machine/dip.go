@@ -0,0 +1,145 @@
+package machine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DIP is the setting of the DIP switches on the Space Invaders board.
+//
+// The switches are read by the game through input port 2:
+//
+// Bits 0-1 -> Number of lives (00 = 3, 01 = 4, 10 = 5, 11 = 6)
+// Bit 3    -> Bonus life at (0 = 1500, 1 = 1000)
+// Bit 7    -> Coin info on demo screen (0 = on, 1 = off)
+type DIP struct {
+	// The number of lives per game, from 3 to 6.
+	Lives int `json:"lives"`
+
+	// The score at which a bonus life is awarded, either 1000 or 1500.
+	BonusLife int `json:"bonus_life"`
+
+	// Show the coin info on the demo screen.
+	CoinInfo bool `json:"coin_info"`
+}
+
+// DefaultDIP returns the factory setting of the DIP switches.
+func DefaultDIP() DIP {
+	return DIP{
+		Lives:     3,
+		BonusLife: 1500,
+		CoinInfo:  true,
+	}
+}
+
+// Validate returns an error if the setting cannot be represented by the DIP
+// switches.
+func (d DIP) Validate() error {
+	if d.Lives < 3 || d.Lives > 6 {
+		return fmt.Errorf("invalid number of lives %d: must be 3, 4, 5 or 6", d.Lives)
+	}
+	if d.BonusLife != 1000 && d.BonusLife != 1500 {
+		return fmt.Errorf("invalid bonus life %d: must be 1000 or 1500", d.BonusLife)
+	}
+
+	return nil
+}
+
+// String returns a human readable summary of the setting.
+func (d DIP) String() string {
+	info := "on"
+	if !d.CoinInfo {
+		info = "off"
+	}
+
+	return fmt.Sprintf(
+		"lives=%d bonus-life=%d coin-info=%s", d.Lives, d.BonusLife, info,
+	)
+}
+
+// Set sets the named switch from its string value. The names are those used
+// by String.
+func (d *DIP) Set(name, value string) (err error) {
+	switch strings.ToLower(name) {
+	case "lives":
+		d.Lives, err = strconv.Atoi(value)
+	case "bonus-life":
+		d.BonusLife, err = strconv.Atoi(value)
+	case "coin-info":
+		switch strings.ToLower(value) {
+		case "on", "true", "1":
+			d.CoinInfo = true
+		case "off", "false", "0":
+			d.CoinInfo = false
+		default:
+			err = fmt.Errorf("invalid coin info %q: must be on or off", value)
+		}
+	default:
+		err = fmt.Errorf("unknown DIP switch %q", name)
+	}
+
+	return err
+}
+
+// bits returns the DIP switch bits of input port 2.
+//
+// The setting must be valid.
+func (d DIP) bits() byte {
+	n := byte(d.Lives-3) & 0x03
+
+	if d.BonusLife == 1000 {
+		n |= 0x01 << 3
+	}
+
+	if !d.CoinInfo {
+		n |= 0x01 << 7
+	}
+
+	return n
+}
+
+// dipFromBits returns the setting represented by the DIP switch bits of input
+// port 2.
+func dipFromBits(n byte) DIP {
+	d := DIP{
+		Lives:     int(n&0x03) + 3,
+		BonusLife: 1500,
+		CoinInfo:  n&(0x01<<7) == 0,
+	}
+	if n&(0x01<<3) != 0 {
+		d.BonusLife = 1000
+	}
+
+	return d
+}
+
+// WithDIP sets the DIP switches.
+func WithDIP(d DIP) Option {
+	return func(m *Machine) {
+		m.dips = d
+	}
+}
+
+// DIP returns the setting of the DIP switches.
+func (m *Machine) DIP() DIP {
+	return m.dips
+}
+
+// SetDIP changes the setting of the DIP switches.
+//
+// As on the original board, the game only reads most switches when a game
+// starts.
+func (m *Machine) SetDIP(d DIP) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	m.dips = d
+
+	return nil
+}
+
+// dip returns the DIP switch bits of input port 2.
+func (m *Machine) dip() byte {
+	return m.dips.bits()
+}
@@ -0,0 +1,125 @@
+package machine
+
+import "testing"
+
+func TestDIPBits(t *testing.T) {
+	// Every setting round trips through the switch bits, which hold the lives
+	// in bits 0-1, a bonus life at 1000 in bit 3 and no coin info in bit 7.
+	for lives := 3; lives <= 6; lives++ {
+		for _, bonus := range []int{1000, 1500} {
+			for _, info := range []bool{true, false} {
+				d := DIP{Lives: lives, BonusLife: bonus, CoinInfo: info}
+				if err := d.Validate(); err != nil {
+					t.Errorf("%s: %v", d, err)
+					continue
+				}
+
+				want := byte(lives - 3)
+				if bonus == 1000 {
+					want |= 0x08
+				}
+				if !info {
+					want |= 0x80
+				}
+
+				n := d.bits()
+				if n != want {
+					t.Errorf("%s: bits = %08b, want %08b", d, n, want)
+				}
+				if got := dipFromBits(n); got != d {
+					t.Errorf("%s: dipFromBits(%08b) = %s", d, n, got)
+				}
+			}
+		}
+	}
+
+	if n := DefaultDIP().bits(); n != 0x00 {
+		t.Errorf("default bits = %08b, want 00000000", n)
+	}
+
+	// The other bits of the port are not DIP switches.
+	for n := 0; n < 0x100; n++ {
+		if got, want := dipFromBits(byte(n)).bits(), byte(n)&0x8b; got != want {
+			t.Errorf("dipFromBits(%08b).bits() = %08b, want %08b", n, got, want)
+		}
+	}
+}
+
+func TestDIPValidate(t *testing.T) {
+	tests := []struct {
+		d     DIP
+		valid bool
+	}{
+		{DefaultDIP(), true},
+		{DIP{Lives: 6, BonusLife: 1000}, true},
+		{DIP{Lives: 2, BonusLife: 1500}, false},
+		{DIP{Lives: 7, BonusLife: 1500}, false},
+		{DIP{Lives: 0, BonusLife: 1500}, false},
+		{DIP{Lives: 3, BonusLife: 1200}, false},
+		{DIP{Lives: 3, BonusLife: 0}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.d.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%+v: error = %v, want valid %t", tt.d, err, tt.valid)
+		}
+	}
+}
+
+func TestDIPSet(t *testing.T) {
+	tests := []struct {
+		name, value string
+		want        DIP
+		ok          bool
+	}{
+		{"lives", "4", DIP{Lives: 4, BonusLife: 1500, CoinInfo: true}, true},
+		{"LIVES", "6", DIP{Lives: 6, BonusLife: 1500, CoinInfo: true}, true},
+		{"bonus-life", "1000", DIP{Lives: 3, BonusLife: 1000, CoinInfo: true}, true},
+		{"coin-info", "off", DIP{Lives: 3, BonusLife: 1500, CoinInfo: false}, true},
+		{"coin-info", "FALSE", DIP{Lives: 3, BonusLife: 1500, CoinInfo: false}, true},
+		{"coin-info", "0", DIP{Lives: 3, BonusLife: 1500, CoinInfo: false}, true},
+		{"coin-info", "on", DIP{Lives: 3, BonusLife: 1500, CoinInfo: true}, true},
+		{"lives", "four", DIP{}, false},
+		{"bonus-life", "", DIP{}, false},
+		{"coin-info", "maybe", DIP{}, false},
+		{"difficulty", "hard", DIP{}, false},
+	}
+	for _, tt := range tests {
+		d := DefaultDIP()
+		err := d.Set(tt.name, tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("Set(%q, %q) error = %v, want ok %t", tt.name, tt.value, err, tt.ok)
+			continue
+		}
+		if tt.ok && d != tt.want {
+			t.Errorf("Set(%q, %q) = %+v, want %+v", tt.name, tt.value, d, tt.want)
+		}
+	}
+
+	// A value that parses may still be out of range for the switches.
+	d := DefaultDIP()
+	if err := d.Set("lives", "7"); err != nil {
+		t.Fatal(err)
+	}
+	if d.Validate() == nil {
+		t.Error("expected 7 lives to be invalid")
+	}
+}
+
+func TestSetDIP(t *testing.T) {
+	m := newTestMachine(t)
+
+	d := DIP{Lives: 5, BonusLife: 1000, CoinInfo: false}
+	if err := m.SetDIP(d); err != nil {
+		t.Fatal(err)
+	}
+	if m.DIP() != d || m.dip() != 0x8a {
+		t.Errorf("DIP = %s with bits %08b, want %s with bits 10001010", m.DIP(), m.dip(), d)
+	}
+
+	if err := m.SetDIP(DIP{Lives: 9, BonusLife: 1000}); err == nil {
+		t.Error("expected an error setting an invalid DIP setting")
+	}
+	if m.DIP() != d {
+		t.Errorf("DIP = %s after an invalid setting, want it unchanged at %s", m.DIP(), d)
+	}
+}
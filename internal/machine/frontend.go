@@ -0,0 +1,227 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+)
+
+// Button represents a logical input on the Space Invaders cabinet, or an
+// emulator hotkey.
+type Button int
+
+// The logical inputs of the Space Invaders cabinet and the emulator.
+const (
+	ButtonCoin Button = iota
+	ButtonP1Start
+	ButtonP2Start
+	ButtonP1Shoot
+	ButtonP1Left
+	ButtonP1Right
+	ButtonP2Shoot
+	ButtonP2Left
+	ButtonP2Right
+	ButtonTilt
+
+	// Emulator hotkeys.
+	ButtonSaveState
+	ButtonLoadState
+	ButtonPrevSlot
+	ButtonNextSlot
+	ButtonRewind
+	ButtonPause
+	ButtonFrameAdvance
+	ButtonSpeedDown
+	ButtonSpeedUp
+	ButtonSpeedReset
+	ButtonDIPMenu
+	ButtonScreenshot
+	ButtonRecordVideo
+	ButtonVolumeDown
+	ButtonVolumeUp
+	ButtonMute
+	ButtonMixerMenu
+	ButtonMonitor
+
+	// numButtons is the number of logical inputs.
+	numButtons
+)
+
+// buttonInfo holds the name and description of every logical input.
+var buttonInfo = [numButtons]struct {
+	name string
+	desc string
+}{
+	ButtonCoin:         {"coin", "Insert coin"},
+	ButtonP1Start:      {"p1-start", "1P start"},
+	ButtonP2Start:      {"p2-start", "2P start"},
+	ButtonP1Shoot:      {"p1-shoot", "1P shoot"},
+	ButtonP1Left:       {"p1-left", "1P left"},
+	ButtonP1Right:      {"p1-right", "1P right"},
+	ButtonP2Shoot:      {"p2-shoot", "2P shoot"},
+	ButtonP2Left:       {"p2-left", "2P left"},
+	ButtonP2Right:      {"p2-right", "2P right"},
+	ButtonTilt:         {"tilt", "Tilt"},
+	ButtonSaveState:    {"save-state", "Save state"},
+	ButtonLoadState:    {"load-state", "Load state"},
+	ButtonPrevSlot:     {"prev-slot", "Prev slot"},
+	ButtonNextSlot:     {"next-slot", "Next slot"},
+	ButtonRewind:       {"rewind", "Rewind (hold)"},
+	ButtonPause:        {"pause", "Pause"},
+	ButtonFrameAdvance: {"frame-advance", "Frame advance"},
+	ButtonSpeedDown:    {"speed-down", "Slower"},
+	ButtonSpeedUp:      {"speed-up", "Faster"},
+	ButtonSpeedReset:   {"speed-reset", "Normal speed"},
+	ButtonDIPMenu:      {"dip-menu", "DIP switch menu"},
+	ButtonScreenshot:   {"screenshot", "Screenshot"},
+	ButtonRecordVideo:  {"record-video", "Start/stop video"},
+	ButtonVolumeDown:   {"volume-down", "Volume down"},
+	ButtonVolumeUp:     {"volume-up", "Volume up"},
+	ButtonMute:         {"mute", "Mute/unmute"},
+	ButtonMixerMenu:    {"mixer-menu", "Mixer menu"},
+	ButtonMonitor:      {"monitor", "Debug monitor"},
+}
+
+// Buttons returns every logical input, cabinet inputs first.
+func Buttons() []Button {
+	bs := make([]Button, numButtons)
+	for i := range bs {
+		bs[i] = Button(i)
+	}
+
+	return bs
+}
+
+// ParseButton returns the logical input with the given name.
+func ParseButton(name string) (Button, error) {
+	for b, bi := range buttonInfo {
+		if bi.name == name {
+			return Button(b), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown input %q", name)
+}
+
+// String returns the name of the button, as used in configuration.
+func (b Button) String() string {
+	if b < 0 || b >= numButtons {
+		return fmt.Sprintf("Button(%d)", int(b))
+	}
+
+	return buttonInfo[b].name
+}
+
+// Description returns a human readable description of the button.
+func (b Button) Description() string {
+	if b < 0 || b >= numButtons {
+		return b.String()
+	}
+
+	return buttonInfo[b].desc
+}
+
+// Screen is the interface that wraps the basic Draw and VRAM methods.
+//
+// Draw calls fn with the co-ordinates of every lit pixel on the screen. The
+// origin is the bottom left corner of the upright (rotated) screen.
+//
+// VRAM returns the raw 1bpp video RAM, for frontends that decode the screen
+// in bulk. The returned slice must not be modified.
+type Screen interface {
+	Draw(fn func(x, y int))
+	VRAM() []byte
+}
+
+// Video is the interface that video frontends are expected to implement.
+//
+// Render presents the given screen.
+//
+// Closed returns true if the frontend has been closed and emulation should
+// stop.
+type Video interface {
+	Render(s Screen)
+	Closed() bool
+}
+
+// Input is the interface that wraps the basic Pressed method.
+//
+// Pressed returns true if the given button is currently pressed.
+type Input interface {
+	Pressed(b Button) bool
+}
+
+// Audio is the interface that audio sinks are expected to implement.
+//
+// Out is called with every value written to sound port 3 or 5, the given
+// number of CPU cycles into the current frame. The sound latches of the
+// original sound board are modelled by the sound engine behind the sink.
+//
+// Frame is called at the end of every emulated frame, and every frame rewound,
+// so that sinks which render sound on the emulated timeline can follow it.
+type Audio interface {
+	Out(port, data byte, cycle int)
+	Frame() error
+}
+
+// Clock is the interface that audio sinks are expected to implement to pace
+// emulation by the sound they play, rather than by wall time.
+//
+// Due returns the number of frames that must be emulated now to keep the sink
+// supplied with sound. Wait returns how long to wait before calling Due again
+// when none are due.
+type Clock interface {
+	Due() int
+	Wait() time.Duration
+}
+
+// Mixer is the interface that audio mixers are expected to implement, so that
+// the sound can be adjusted while the machine runs.
+//
+// Volume returns the master volume and SetVolume sets it, clamped to the range
+// the mixer supports. Muted returns true if all sound is muted and SetMuted
+// mutes or unmutes it.
+//
+// Set sets the named setting from its string value, and String returns a
+// human readable summary of every setting, in the form accepted by Set.
+type Mixer interface {
+	Volume() float64
+	SetVolume(v float64)
+	Muted() bool
+	SetMuted(muted bool)
+	Set(name, value string) error
+	String() string
+}
+
+// Recorder is the interface that video recorders are expected to implement.
+//
+// Frame records the screen at the end of every emulated frame, so that the
+// recording follows the emulated timeline regardless of the emulation speed.
+//
+// Sound records a value written to sound port 3 or 5 the given number of CPU
+// cycles into the current frame.
+//
+// Close finishes the recording.
+type Recorder interface {
+	Frame(s Screen) error
+	Sound(port, data byte, cycle int)
+	Close() error
+}
+
+// nop is a frontend that discards video and audio and never reports input. It
+// is used in place of any frontend that is not supplied to the machine.
+type nop struct{}
+
+// Render implements Video.
+func (nop) Render(Screen) {}
+
+// Closed implements Video.
+func (nop) Closed() bool { return false }
+
+// Pressed implements Input.
+func (nop) Pressed(Button) bool { return false }
+
+// Out implements Audio.
+func (nop) Out(byte, byte, int) {}
+
+// Frame implements Audio.
+func (nop) Frame() error { return nil }
@@ -1,32 +1,16 @@
 package machine
 
-import (
-	"image/color"
-
-	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/imdraw"
-)
-
-// render renders the current screen to the window.
+// render renders the current screen to the video frontend.
 func (m *Machine) render() {
-	// Prepare the drawing object.
-	imd := imdraw.New(nil)
-	imd.Color = color.White
-
-	// Draw the screen.
-	m.draw(imd)
-
-	// Update the window.
-	imd.Draw(m.w)
-	m.w.Update()
+	m.v.Render(m)
 }
 
-// draw draws the current screen onto the given draw object.
+// Draw calls fn with the co-ordinates of every lit pixel on the current screen.
 //
 // The screen is drawn by iterating over the range of memory between the VRAM
 // start address and the start address plus 256*224 bytes. Each byte in this
 // range represents 8 pixels.
-func (m *Machine) draw(imd *imdraw.IMDraw) {
+func (m *Machine) Draw(fn func(x, y int)) {
 	var (
 		bit  uint = 0
 		vb   uint8
@@ -42,7 +26,7 @@ func (m *Machine) draw(imd *imdraw.IMDraw) {
 
 			// Check if the pixel is lit.
 			if (vb>>bit)&0x01 != 0x00 {
-				m.pixel(imd, x, y)
+				fn(x, y)
 			}
 
 			// Move on to the next bit.
@@ -56,15 +40,11 @@ func (m *Machine) draw(imd *imdraw.IMDraw) {
 	}
 }
 
-// pixel draws a pixel to the draw object at the give co-ordinates.
+// VRAM returns the contents of video RAM, one bit per pixel. Each byte holds 8
+// vertically adjacent pixels of a column, starting at the bottom left of the
+// upright screen.
 //
-// The pixel is scaled to a size determined by the scale factor.
-func (m *Machine) pixel(imd *imdraw.IMDraw, x, y int) {
-	x1 := float64(x * m.sf)
-	y1 := float64(y * m.sf)
-	imd.Push(
-		pixel.V(x1, y1),
-		pixel.V(x1+float64(m.sf), y1+float64(m.sf)),
-	)
-	imd.Rectangle(0)
+// The returned slice refers to the machine memory and must not be modified.
+func (m *Machine) VRAM() []byte {
+	return m.mem.ReadAll()[vramStart : int(vramStart)+vramSize]
 }
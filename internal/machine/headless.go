@@ -0,0 +1,69 @@
+package machine
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+const (
+	// The RAM, including video RAM, occupies the 8K following the ROM.
+	ramStart uint16 = 0x2000
+	ramSize         = 0x2000
+)
+
+// RunFrames emulates the machine as fast as possible, without rendering, for
+// the given number of frames or until stop returns true, whichever happens
+// first.
+//
+// A frame count of zero runs until stop returns true. A nil stop condition is
+// never met. Emulation also ends early if the CPU halts. The number of frames
+// emulated is returned.
+func (m *Machine) RunFrames(frames int, stop func() bool) (n int, err error) {
+	for frames == 0 || n < frames {
+		if !m.c.Running() || (stop != nil && stop()) {
+			break
+		}
+
+		if err = m.step(); err != nil {
+			return n, fmt.Errorf("step: %w", err)
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+// Image returns the current screen as a 1bpp upright image.
+func (m *Machine) Image() *image.Paletted {
+	img := image.NewPaletted(
+		image.Rect(0, 0, screenW, screenH),
+		color.Palette{color.Black, color.White},
+	)
+
+	// Draw co-ordinates have their origin at the bottom left, whereas image
+	// co-ordinates have their origin at the top left.
+	m.Draw(func(x, y int) {
+		img.SetColorIndex(x, screenH-1-y, 1)
+	})
+
+	return img
+}
+
+// WritePNG writes the current screen to w as a PNG.
+func (m *Machine) WritePNG(w io.Writer) error {
+	return png.Encode(w, m.Image())
+}
+
+// DumpRAM writes the contents of the RAM, including video RAM, to w.
+func (m *Machine) DumpRAM(w io.Writer) error {
+	ram := make([]byte, ramSize)
+	for i := range ram {
+		ram[i] = m.mem.Read(ramStart + uint16(i))
+	}
+
+	_, err := w.Write(ram)
+	return err
+}
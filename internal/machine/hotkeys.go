@@ -0,0 +1,142 @@
+package machine
+
+import (
+	"log"
+	"math"
+)
+
+// volumeStep is the change in the master volume made by the volume hotkeys.
+const volumeStep = 0.1
+
+// hotkeyButtons lists the buttons that trigger emulator actions.
+var hotkeyButtons = []Button{
+	ButtonSaveState,
+	ButtonLoadState,
+	ButtonPrevSlot,
+	ButtonNextSlot,
+	ButtonPause,
+	ButtonFrameAdvance,
+	ButtonSpeedDown,
+	ButtonSpeedUp,
+	ButtonSpeedReset,
+	ButtonDIPMenu,
+	ButtonScreenshot,
+	ButtonRecordVideo,
+	ButtonVolumeDown,
+	ButtonVolumeUp,
+	ButtonMute,
+	ButtonMixerMenu,
+	ButtonMonitor,
+}
+
+// hotkeys performs the action of any hotkey that has been pressed since the
+// previous frame.
+//
+// Hotkeys act once per press, rather than for as long as they are held.
+func (m *Machine) hotkeys() {
+	for _, b := range hotkeyButtons {
+		pressed := m.in.Pressed(b)
+		if pressed && !m.held[b] {
+			m.hotkey(b)
+		}
+		m.held[b] = pressed
+	}
+}
+
+// hotkey performs the action of the given hotkey.
+func (m *Machine) hotkey(b Button) {
+	switch b {
+	case ButtonSaveState:
+		if err := m.SaveSlot(m.slot); err != nil {
+			log.Printf("save state to slot %d: %v", m.slot, err)
+			return
+		}
+		log.Printf("saved state to slot %d", m.slot)
+	case ButtonLoadState:
+		if m.movieActive() {
+			log.Print("cannot load state while a movie is recording or playing")
+			return
+		}
+		if err := m.LoadSlot(m.slot); err != nil {
+			log.Printf("load state from slot %d: %v", m.slot, err)
+			return
+		}
+		log.Printf("loaded state from slot %d", m.slot)
+	case ButtonPrevSlot:
+		m.slot = (m.slot + stateSlots - 1) % stateSlots
+		log.Printf("selected save state slot %d", m.slot)
+	case ButtonNextSlot:
+		m.slot = (m.slot + 1) % stateSlots
+		log.Printf("selected save state slot %d", m.slot)
+	case ButtonPause:
+		m.pc.paused = !m.pc.paused
+		if m.pc.paused {
+			log.Print("paused")
+		} else {
+			log.Print("resumed")
+		}
+	case ButtonFrameAdvance:
+		if m.pc.paused {
+			m.pc.advance = true
+		}
+	case ButtonSpeedDown:
+		m.pc.slower()
+		log.Printf("speed %gx", speeds[m.pc.speed])
+	case ButtonSpeedUp:
+		m.pc.faster()
+		log.Printf("speed %gx", speeds[m.pc.speed])
+	case ButtonSpeedReset:
+		m.pc.speed = normalSpeed
+		log.Printf("speed %gx", speeds[m.pc.speed])
+	case ButtonDIPMenu:
+		m.dipMenu()
+	case ButtonScreenshot:
+		path, err := m.Screenshot()
+		if err != nil {
+			log.Printf("screenshot: %v", err)
+			return
+		}
+		log.Printf("saved screenshot to %s", path)
+	case ButtonRecordVideo:
+		if err := m.toggleRecording(); err != nil {
+			log.Printf("record video: %v", err)
+			return
+		}
+		if m.Recording() {
+			log.Print("started recording video")
+		} else {
+			log.Print("stopped recording video")
+		}
+	case ButtonVolumeDown, ButtonVolumeUp, ButtonMute, ButtonMixerMenu:
+		if m.mx == nil {
+			log.Print("no audio mixer")
+			return
+		}
+		m.mixerHotkey(b)
+	case ButtonMonitor:
+		m.monitor("")
+	}
+}
+
+// mixerHotkey performs the action of the given mixer hotkey.
+func (m *Machine) mixerHotkey(b Button) {
+	switch b {
+	case ButtonVolumeDown, ButtonVolumeUp:
+		step := volumeStep
+		if b == ButtonVolumeDown {
+			step = -step
+		}
+		// Round to whole steps, so that repeated steps do not drift.
+		m.mx.SetVolume(math.Round((m.mx.Volume()+step)/volumeStep) * volumeStep)
+		log.Printf("volume %.0f%%", m.mx.Volume()*100)
+	case ButtonMute:
+		m.mx.SetMuted(!m.mx.Muted())
+		if m.mx.Muted() {
+			log.Print("muted")
+		} else {
+			log.Print("unmuted")
+		}
+	case ButtonMixerMenu:
+		m.mixerMenu()
+	}
+}
@@ -2,8 +2,6 @@ package machine
 
 import (
 	"fmt"
-
-	"github.com/faiface/pixel/pixelgl"
 )
 
 // input returns input parsed from the given port.
@@ -18,67 +16,9 @@ func (m *Machine) input(port byte) byte {
 		// There technically is a port 0 in the Space Invaders hardware, but
 		// it does not get used by the software.
 	case 1:
-		// Bit 3 is always 1.
-		n |= 0x01 << 3
-
-		// Credit.
-		if m.w.Pressed(pixelgl.KeyC) {
-			n |= 0x01
-		}
-
-		// 1P start.
-		if m.w.Pressed(pixelgl.Key1) {
-			n |= 0x01 << 2
-		}
-
-		// 2P start.
-		if m.w.Pressed(pixelgl.Key2) {
-			n |= 0x01 << 1
-		}
-
-		// 1P shot.
-		if m.w.Pressed(pixelgl.KeyW) {
-			n |= 0x01 << 4
-		}
-
-		// 1P left.
-		if m.w.Pressed(pixelgl.KeyQ) {
-			n |= 0x01 << 5
-		}
-
-		// 1P right.
-		if m.w.Pressed(pixelgl.KeyE) {
-			n |= 0x01 << 6
-		}
+		n = m.in1
 	case 2:
-		// 0 = 3 lives. 10 = 5 lives.
-		n |= 0x00 << 0
-
-		// 0 = extra life at 1500. 1 = extra life at 1000.
-		n |= 0x00 << 3
-
-		// Coin info on demo screen. 0 = ON.
-		n |= 0x00 << 7
-
-		// Tilt.
-		if m.w.Pressed(pixelgl.KeyT) {
-			n |= 0x01 << 2
-		}
-
-		// 2P shot.
-		if m.w.Pressed(pixelgl.KeyO) {
-			n |= 0x01 << 4
-		}
-
-		// 2P left.
-		if m.w.Pressed(pixelgl.KeyI) {
-			n |= 0x01 << 5
-		}
-
-		// 2P right.
-		if m.w.Pressed(pixelgl.KeyP) {
-			n |= 0x01 << 6
-		}
+		n = m.in2
 	case 3:
 		// Result of the shift register.
 		n = uint8((m.sd >> (8 - m.so)) & 0xff)
@@ -86,3 +26,82 @@ func (m *Machine) input(port byte) byte {
 
 	return n
 }
+
+// latchInput latches the state of the cabinet controls for the coming frame.
+//
+// The controls are read once per frame, rather than on every IN instruction,
+// so that a frame's input can be recorded and played back exactly.
+func (m *Machine) latchInput() {
+	m.in1 = m.port1()
+	m.in2 = m.port2()
+
+	if m.mv != nil {
+		m.movieInput()
+	}
+}
+
+// port1 returns the state of input port 1 from the input frontend.
+func (m *Machine) port1() byte {
+	// Bit 3 is always 1.
+	n := byte(0x01 << 3)
+
+	// Credit.
+	if m.in.Pressed(ButtonCoin) {
+		n |= 0x01
+	}
+
+	// 1P start.
+	if m.in.Pressed(ButtonP1Start) {
+		n |= 0x01 << 2
+	}
+
+	// 2P start.
+	if m.in.Pressed(ButtonP2Start) {
+		n |= 0x01 << 1
+	}
+
+	// 1P shot.
+	if m.in.Pressed(ButtonP1Shoot) {
+		n |= 0x01 << 4
+	}
+
+	// 1P left.
+	if m.in.Pressed(ButtonP1Left) {
+		n |= 0x01 << 5
+	}
+
+	// 1P right.
+	if m.in.Pressed(ButtonP1Right) {
+		n |= 0x01 << 6
+	}
+
+	return n
+}
+
+// port2 returns the state of input port 2 from the DIP switches and the input
+// frontend.
+func (m *Machine) port2() byte {
+	n := m.dip()
+
+	// Tilt.
+	if m.in.Pressed(ButtonTilt) {
+		n |= 0x01 << 2
+	}
+
+	// 2P shot.
+	if m.in.Pressed(ButtonP2Shoot) {
+		n |= 0x01 << 4
+	}
+
+	// 2P left.
+	if m.in.Pressed(ButtonP2Left) {
+		n |= 0x01 << 5
+	}
+
+	// 2P right.
+	if m.in.Pressed(ButtonP2Right) {
+		n |= 0x01 << 6
+	}
+
+	return n
+}
@@ -0,0 +1,68 @@
+package machine
+
+import (
+	cpu "github.com/danmrichards/go8080"
+)
+
+// Flag bit positions in the program status word.
+const (
+	flagCY = 1 << 0
+	flagP  = 1 << 2
+	flagAC = 1 << 4
+	flagZ  = 1 << 6
+	flagS  = 1 << 7
+)
+
+// intel8080 adapts the go8080 CPU to the processor interface.
+type intel8080 struct {
+	*cpu.Intel8080
+}
+
+// newIntel8080 returns the given go8080 CPU adapted to the processor
+// interface.
+func newIntel8080(c *cpu.Intel8080) intel8080 {
+	return intel8080{c}
+}
+
+// State returns a snapshot of the CPU registers, flags and status.
+func (i intel8080) State() cpuState {
+	s := i.Intel8080.State()
+
+	return cpuState{
+		A:      s.R[cpu.A],
+		B:      s.R[cpu.B],
+		C:      s.R[cpu.C],
+		D:      s.R[cpu.D],
+		E:      s.R[cpu.E],
+		H:      s.R[cpu.H],
+		L:      s.R[cpu.L],
+		Flags:  s.Status,
+		SP:     s.SP,
+		PC:     s.PC,
+		IE:     s.IE,
+		Halted: s.Halted,
+		Cycles: s.Cycles,
+	}
+}
+
+// SetState restores the CPU registers, flags and status from a snapshot.
+func (i intel8080) SetState(s cpuState) {
+	var r [8]byte
+	r[cpu.A] = s.A
+	r[cpu.B] = s.B
+	r[cpu.C] = s.C
+	r[cpu.D] = s.D
+	r[cpu.E] = s.E
+	r[cpu.H] = s.H
+	r[cpu.L] = s.L
+
+	i.Intel8080.SetState(cpu.State{
+		R:      r,
+		Status: s.Flags,
+		SP:     s.SP,
+		PC:     s.PC,
+		IE:     s.IE,
+		Halted: s.Halted,
+		Cycles: s.Cycles,
+	})
+}
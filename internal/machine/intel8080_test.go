@@ -0,0 +1,55 @@
+package machine
+
+import "testing"
+
+func TestIntel8080State(t *testing.T) {
+	m := newTestMachine(t)
+
+	tests := []cpuState{
+		{
+			A: 0x01, B: 0x02, C: 0x03, D: 0x04, E: 0x05, H: 0x06, L: 0x07,
+			Flags:  0x02 | flagCY | flagP | flagAC | flagZ | flagS,
+			SP:     0x23fe,
+			PC:     0x0020,
+			IE:     true,
+			Halted: false,
+			Cycles: 0x12345678,
+		},
+		{
+			A: 0xff, B: 0xfe, C: 0xfd, D: 0xfc, E: 0xfb, H: 0xfa, L: 0xf9,
+			Flags:  0x02 | flagZ | flagCY,
+			SP:     0x0000,
+			PC:     0xffff,
+			IE:     false,
+			Halted: true,
+		},
+	}
+	for _, want := range tests {
+		m.c.SetState(want)
+		if got := m.c.State(); got != want {
+			t.Errorf("state = %+v, want %+v", got, want)
+		}
+		if got := m.c.PC(); got != want.PC {
+			t.Errorf("PC = %04x, want %04x", got, want.PC)
+		}
+		if got := m.c.InterruptsEnabled(); got != want.IE {
+			t.Errorf("interrupts enabled = %t, want %t", got, want.IE)
+		}
+	}
+}
+
+func TestIntel8080SetStateRuns(t *testing.T) {
+	m := newTestMachine(t)
+
+	// The CPU must run from the restored state: LXI SP at $0020 loads SP and
+	// moves PC on by three.
+	m.c.SetState(cpuState{Flags: 0x02, PC: 0x0020})
+	if err := m.c.Step(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.c.State()
+	if s.SP != 0x2400 || s.PC != 0x0023 {
+		t.Errorf("SP, PC = %04x, %04x, want 2400, 0023", s.SP, s.PC)
+	}
+}
@@ -1,31 +1,47 @@
 package machine
 
 import (
-	"image/color"
+	"bufio"
+	"fmt"
+	"image"
 	"log"
+	"os"
 	"time"
 
-	"github.com/danmrichards/go-invaders/internal/sound"
 	cpu "github.com/danmrichards/go8080"
-	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
 )
 
 const (
-	// Screen dimensions. The native Space Invaders resolution is 224x256, but
-	// we're also adding a scale factor to allow rendering at a higher
-	// resolution on modern displays.
+	// Screen dimensions. The native Space Invaders resolution is 224x256.
 	screenW, screenH = 224, 256
 
-	// The original Space Invaders machine ran at a clock speed of 2MHz. The
-	// screen refreshed at a rate of 60Hz. Make some rough calculations at how
-	// many CPU cycles we should process per frame.
-	screenRefresh         = 60
-	clockSpeed            = 2000000
-	cyclesPerFrame uint32 = clockSpeed / screenRefresh
-
-	// The video RAM starts at address 0x2400 in the machine memory.
+	// The original Space Invaders machine was driven by a 19.968MHz crystal.
+	// The CPU ran at a tenth of that, 1.9968MHz. The video ran at a quarter
+	// of that, drawing 262 lines of 320 pixels (including blanking) per frame
+	// for a refresh rate of ~59.54Hz. That works out at exactly 128 CPU cycles
+	// per line.
+	clockSpeed                = 1996800
+	linesPerFrame             = 262
+	cyclesPerLine      uint32 = 128
+	cyclesPerFrame            = cyclesPerLine * linesPerFrame
+	midScreenLine      uint32 = 96
+	vblankLine         uint32 = 224
+	midScreenInterrupt uint16 = 0x08
+	vblankInterrupt    uint16 = 0x10
+
+	// The opcode of EI, the instruction that enables interrupts.
+	opEI = 0xfb
+
+	// ClockSpeed is the CPU clock speed in Hz and CyclesPerFrame the number
+	// of CPU cycles in each frame. Together they define the emulated
+	// timeline.
+	ClockSpeed     = clockSpeed
+	CyclesPerFrame = int(cyclesPerFrame)
+
+	// The video RAM starts at address 0x2400 in the machine memory and holds
+	// 1 bit per pixel.
 	vramStart uint16 = 0x2400
+	vramSize         = screenW * screenH / 8
 )
 
 type (
@@ -43,17 +59,25 @@ type (
 		// For more details on the ROM structure see LoadROM.
 		mem cpu.MemReadWriter
 
-		// The render window.
-		w *pixelgl.Window
+		// The frontends the machine renders to and reads input from, and the
+		// sink it plays sound through.
+		v  Video
+		in Input
+		a  Audio
 
-		// Sound player.
-		p *sound.Player
+		// The number of CPU cycles emulated so far in the current frame.
+		fc uint32
 
-		// The address of the next interrupt to send to the CPU.
-		ni uint16
+		// The address of the interrupt requested by the video hardware and
+		// not yet accepted by the CPU, or zero if there is none. The request
+		// is held until the CPU enables interrupts.
+		pi uint16
 
-		// The video scale factor.
-		sf int
+		// Set while the last instruction executed was EI. The 8080 accepts
+		// interrupts only once the instruction after EI has executed, so that
+		// an interrupt handler can return with EI, RET before the next one is
+		// taken.
+		ei bool
 
 		// The Intel 8080 does not include opcodes for shifting by anything
 		// other than 1 bit. Hence it would take thousands of instruction calls
@@ -67,12 +91,61 @@ type (
 		// Watchdog (read or write to reset).
 		wd byte
 
-		// Sound banks which store the currently playing sound.
+		// The setting of the DIP switches.
+		dips DIP
+
+		// The state of input ports 1 and 2, latched at the start of each
+		// frame.
+		in1 byte
+		in2 byte
+
+		// The movie being recorded or played back, nil if there is none.
+		mv *movie
+
+		// Set once the first frame has been emulated, after which a movie
+		// can no longer start in step with power on.
+		ran bool
+
+		// The mixer adjusted by the volume hotkeys, nil if there is none.
+		mx Mixer
+
+		// The values last written to sound ports 3 and 5.
 		snd1 byte
 		snd2 byte
 
 		// Flag for debug mode.
 		debug bool
+
+		// The directory save state slots are stored in, and the selected
+		// slot.
+		sdir string
+		slot int
+
+		// The directory screenshots are written to, and the function that
+		// renders them, nil for the raw screen.
+		shdir string
+		shot  func(s Screen) image.Image
+
+		// The video recording in progress, nil if there is none, and the
+		// function that starts a new one.
+		rec    Recorder
+		newRec func() (Recorder, error)
+
+		// Snapshot history for rewinding, nil if rewinding is disabled.
+		rw *rewinder
+
+		// Paces emulation in real time.
+		pc pacer
+
+		// The text console used for interactive menus and the debug monitor.
+		con console
+
+		// The state of the debug monitor.
+		mon monitor
+
+		// Tracks which hotkeys, and the rewind button, were pressed on the
+		// previous frame, so that hotkeys act once per press.
+		held [numButtons]bool
 	}
 
 	// Option is a functional option that modifies a field on the machine.
@@ -86,24 +159,74 @@ func WithDebugEnabled() Option {
 	}
 }
 
-// WithScaleFactor sets the video scale factor.
-func WithScaleFactor(sf int) Option {
+// WithVideo sets the video frontend the machine renders to.
+func WithVideo(v Video) Option {
+	return func(m *Machine) {
+		m.v = v
+	}
+}
+
+// WithInput sets the input frontend the machine reads the cabinet controls
+// from.
+func WithInput(in Input) Option {
+	return func(m *Machine) {
+		m.in = in
+	}
+}
+
+// WithAudio sets the audio sink the machine plays sound through.
+func WithAudio(a Audio) Option {
+	return func(m *Machine) {
+		m.a = a
+	}
+}
+
+// WithClock paces emulation at normal speed by the given clock, such as the
+// audio sink, rather than by wall time.
+func WithClock(c Clock) Option {
 	return func(m *Machine) {
-		m.sf = sf
+		m.pc.clk = c
+	}
+}
+
+// WithMixer sets the mixer adjusted by the volume hotkeys and mixer menu.
+func WithMixer(mx Mixer) Option {
+	return func(m *Machine) {
+		m.mx = mx
 	}
 }
 
 // New returns an instantiated Space Invaders machine.
+//
+// Any frontend that is not supplied via an option is replaced with one that
+// discards output and never reports input, allowing the machine to run
+// headless.
 func New(mem cpu.MemReadWriter, opts ...Option) (m *Machine, err error) {
 	m = &Machine{
-		mem: mem,
-		ni:  0x08,
+		mem:   mem,
+		v:     nop{},
+		in:    nop{},
+		a:     nop{},
+		dips:  DefaultDIP(),
+		sdir:  "states",
+		shdir: "screenshots",
+		con: console{
+			in:  bufio.NewScanner(os.Stdin),
+			out: os.Stdout,
+		},
+		pc: pacer{
+			speed: normalSpeed,
+		},
 	}
 
 	for _, o := range opts {
 		o(m)
 	}
 
+	if err = m.dips.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Instantiate the CPU.
 	copts := []cpu.Option{
 		cpu.WithInput(m.input),
@@ -112,84 +235,176 @@ func New(mem cpu.MemReadWriter, opts ...Option) (m *Machine, err error) {
 	if m.debug {
 		copts = append(copts, cpu.WithDebugEnabled())
 	}
-	m.c = cpu.NewIntel8080(mem, copts...)
-
-	m.p, err = sound.NewPlayer()
-	if err != nil {
-		return nil, err
-	}
+	m.c = newIntel8080(cpu.NewIntel8080(mem, copts...))
 
 	return m, nil
 }
 
-// Run emulates the Space Invaders machine.
-func (m *Machine) Run() {
-	var err error
-	m.w, err = pixelgl.NewWindow(pixelgl.WindowConfig{
-		Title:  "Space Invaders",
-		Bounds: pixel.R(0, 0, screenW*float64(m.sf), screenH*float64(m.sf)),
-		VSync:  true,
-	})
-	if err != nil {
-		log.Fatalf("create window: %v", err)
-	}
-
-	start := time.Now()
-
-	for !m.w.Closed() && m.c.Running() {
-		// Throttle to one step per ~16ms, to better reproduce the speed of the
-		// original machine.
-		dt := time.Since(start).Milliseconds()
-		if float64(dt) > (1/float64(screenRefresh))*1000 {
-			if err := m.step(); err != nil {
-				log.Fatalf("step: %v\n", err)
+// Run emulates the Space Invaders machine until the CPU halts or the video
+// frontend is closed.
+//
+// Emulation is paced to the refresh rate of the original machine, scaled by
+// the selected speed, regardless of the refresh rate of the video frontend. At
+// normal speed it is paced by the clock set with WithClock, if there is one.
+func (m *Machine) Run() error {
+	m.pc.reset()
+
+	for !m.v.Closed() && m.c.Running() {
+		m.hotkeys()
+
+		n := m.pc.due()
+		if n == 0 {
+			// Keep polling the frontend while paused, without spinning.
+			if m.pc.paused {
+				m.render()
 			}
-			m.render()
+			time.Sleep(m.pc.wait())
+			continue
+		}
+
+		for i := 0; i < n; i++ {
+			if err := m.frame(); err != nil {
+				return err
+			}
+		}
+		m.render()
+	}
+
+	return nil
+}
+
+// frame emulates a single frame, or rewinds by a single snapshot, with the
+// sound muted, while the rewind button is held.
+//
+// Rewinding is refused while a movie is recording or playing, as the movie
+// could no longer be played back faithfully.
+func (m *Machine) frame() error {
+	rewind := m.rw != nil && m.in.Pressed(ButtonRewind)
+	pressed := rewind && !m.held[ButtonRewind]
+	m.held[ButtonRewind] = rewind
+
+	if rewind && m.movieActive() {
+		if pressed {
+			log.Print("cannot rewind while a movie is recording or playing")
 		}
+		rewind = false
+	}
+
+	if rewind {
+		m.rewind()
+
+		// Keep the sound timeline moving, so that rewinding is paced like
+		// emulation.
+		if err := m.a.Frame(); err != nil {
+			return fmt.Errorf("audio: %w", err)
+		}
+		return nil
+	}
+	m.unmute()
 
-		// Clear the screen, ready for the next frame.
-		m.w.Clear(color.Black)
+	if err := m.step(); err != nil {
+		return fmt.Errorf("step: %w", err)
 	}
+	if m.rw != nil {
+		m.capture()
+	}
+
+	return nil
 }
 
-// step performs the core CPU emulation for the machine.
+// step performs the core CPU emulation for the machine, for one frame.
 //
-// The space invaders machine handles it's frame rendering in two parts. It
-// would draw the top half of the screen and then send the first interrupt
-// (RST 8). It would then move on, draw the lower half of the screen and
-// send the second interrupt (RST 10).
+// The space invaders machine handles it's frame rendering in two parts. When
+// the beam reaches the middle of the screen (line 96) the video hardware
+// requests the first interrupt (RST 1), letting the game redraw the top half
+// of the screen. When the beam reaches the start of vertical blanking (line
+// 224) it requests the second interrupt (RST 2), letting the game redraw the
+// bottom half.
 func (m *Machine) step() error {
-	// Work out how many CPU cycles to run in half a frame. This will
-	// synchronise the emulation process with the rendering process in mem.render.
-	hfc := cyclesPerFrame / 2
-
-	// Run the cycles for the first half of the frame, recording the delta in
-	// cycle count at each step call.
-	cyc := uint32(0)
-	for cyc <= hfc {
-		sc := m.c.Cycles()
-		if err := m.c.Step(); err != nil {
-			return err
+	m.ran = true
+
+	if m.mon.frame {
+		m.monitor("")
+	}
+
+	m.latchInput()
+
+	if err := m.runUntil(midScreenLine * cyclesPerLine); err != nil {
+		return err
+	}
+	m.interrupt(midScreenInterrupt)
+
+	if err := m.runUntil(vblankLine * cyclesPerLine); err != nil {
+		return err
+	}
+	m.interrupt(vblankInterrupt)
+
+	if err := m.runUntil(cyclesPerFrame); err != nil {
+		return err
+	}
+
+	// Carry any cycles that overran the frame into the next one.
+	m.fc -= cyclesPerFrame
+
+	if err := m.a.Frame(); err != nil {
+		return fmt.Errorf("audio: %w", err)
+	}
+
+	if m.rec != nil {
+		if err := m.rec.Frame(m); err != nil {
+			return fmt.Errorf("record video: %w", err)
 		}
-		cyc += m.c.Cycles() - sc
 	}
 
-	// Fire off the first interrupt and determine what the next one should be.
-	m.c.Interrupt(m.ni)
-	if m.ni == 0x08 {
-		m.ni = 0x10
-	} else {
-		m.ni = 0x08
+	if m.mv != nil {
+		return m.movieFrame()
 	}
 
-	// Run the cycles for the second half of the frame.
-	for cyc <= cyclesPerFrame {
+	return nil
+}
+
+// runUntil runs the CPU until the given number of cycles into the current
+// frame have been emulated.
+func (m *Machine) runUntil(cyc uint32) error {
+	for m.fc < cyc {
+		if m.mon.armed() {
+			m.debugBreak()
+		}
+
+		opc := m.mem.Read(m.c.PC())
 		sc := m.c.Cycles()
 		if err := m.c.Step(); err != nil {
 			return err
 		}
-		cyc += m.c.Cycles() - sc
+		m.mon.stepped()
+		m.ei = opc == opEI
+		m.acceptInterrupt()
+		m.fc += m.c.Cycles() - sc
 	}
 
 	return nil
 }
+
+// interrupt requests the interrupt with the given address.
+//
+// The request replaces any request that is still pending, and is accepted as
+// soon as the CPU has interrupts enabled and is not executing the instruction
+// after EI.
+func (m *Machine) interrupt(addr uint16) {
+	m.pi = addr
+
+	sc := m.c.Cycles()
+	m.acceptInterrupt()
+	m.fc += m.c.Cycles() - sc
+}
+
+// acceptInterrupt sends the pending interrupt to the CPU if it has interrupts
+// enabled, and the last instruction executed was not EI.
+func (m *Machine) acceptInterrupt() {
+	if m.pi == 0 || m.ei || !m.c.InterruptsEnabled() {
+		return
+	}
+
+	m.c.Interrupt(m.pi)
+	m.pi = 0
+}
@@ -0,0 +1,126 @@
+package machine
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/danmrichards/go-invaders/internal/memory"
+)
+
+// testProgram is a small program for the test machine, keyed by address. The
+// main loop mixes input port 1 into the RAM at $2000, and both interrupt
+// handlers count the interrupts at $2001, so the RAM depends on the input of
+// every frame and the timing of every interrupt.
+var testProgram = map[uint16][]byte{
+	// Reset, RST 1 and RST 2.
+	0x0000: {0xc3, 0x20, 0x00}, // JMP $0020
+	0x0008: {0xc3, 0x40, 0x00}, // JMP $0040
+	0x0010: {0xc3, 0x40, 0x00}, // JMP $0040
+
+	0x0020: {
+		0x31, 0x00, 0x24, // LXI SP,#$2400
+		0xfb,       // EI
+		0xdb, 0x01, // IN 1
+		0x47,             // MOV B,A
+		0x3a, 0x00, 0x20, // LDA $2000
+		0x80,             // ADD B
+		0x07,             // RLC
+		0x32, 0x00, 0x20, // STA $2000
+		0xc3, 0x24, 0x00, // JMP $0024
+	},
+
+	0x0040: {
+		0xf5,             // PUSH PSW
+		0x3a, 0x01, 0x20, // LDA $2001
+		0x3c,             // INR A
+		0x32, 0x01, 0x20, // STA $2001
+		0xf1, // POP PSW
+		0xfb, // EI
+		0xc9, // RET
+	},
+}
+
+// testInput is an input frontend that reports the buttons pressed on each
+// frame, as counted by the test.
+type testInput struct {
+	frame   int
+	pressed func(frame int, b Button) bool
+}
+
+// Pressed implements Input.
+func (in *testInput) Pressed(b Button) bool {
+	return in.pressed(in.frame, b)
+}
+
+// newTestMachine returns a machine running testProgram.
+func newTestMachine(t *testing.T, opts ...Option) *Machine {
+	t.Helper()
+
+	mem := make(memory.Basic, 0x4000)
+	for addr, code := range testProgram {
+		copy(mem[addr:], code)
+	}
+
+	m, err := New(mem, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+// dumpRAM returns the contents of the RAM of the machine.
+func dumpRAM(t *testing.T, m *Machine) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := m.DumpRAM(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	return b.Bytes()
+}
+
+func TestInterruptAfterEI(t *testing.T) {
+	m := newTestMachine(t)
+	for i, b := range []byte{
+		0xfb, // EI
+		0x00, // NOP
+		0x00, // NOP
+	} {
+		m.mem.Write(0x0100+uint16(i), b)
+	}
+	// stepOne runs a single instruction.
+	stepOne := func() {
+		t.Helper()
+		if err := m.runUntil(m.fc + 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The interrupt must wait for the instruction after EI, whether it was
+	// requested before EI or straight after it.
+	for _, early := range []bool{true, false} {
+		m.c.SetState(cpuState{Flags: 0x02, SP: 0x2400, PC: 0x0100})
+		m.pi, m.ei = 0, false
+
+		if early {
+			m.interrupt(vblankInterrupt)
+		}
+		stepOne()
+		if !early {
+			m.interrupt(vblankInterrupt)
+		}
+		if got := m.c.PC(); got != 0x0101 {
+			t.Fatalf("PC = %04x after EI, want 0101", got)
+		}
+
+		stepOne()
+		if got := m.c.PC(); got != vblankInterrupt {
+			t.Fatalf("PC = %04x after the instruction after EI, want %04x", got, vblankInterrupt)
+		}
+		if ret := uint16(m.mem.Read(0x23fe)) | uint16(m.mem.Read(0x23ff))<<8; ret != 0x0102 {
+			t.Errorf("return address = %04x, want 0102", ret)
+		}
+	}
+}
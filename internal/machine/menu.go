@@ -0,0 +1,105 @@
+package machine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// console is the text console used for interactive menus.
+type console struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// WithConsole sets the reader and writer used for interactive menus.
+func WithConsole(r io.Reader, w io.Writer) Option {
+	return func(m *Machine) {
+		m.con = console{
+			in:  bufio.NewScanner(r),
+			out: w,
+		}
+	}
+}
+
+// prompt writes the prompt to the console and returns the next line entered,
+// trimmed of white space. The returned bool is false if there is no more
+// input.
+func (c console) prompt(p string) (string, bool) {
+	fmt.Fprint(c.out, p)
+	if !c.in.Scan() {
+		return "", false
+	}
+
+	return strings.TrimSpace(c.in.Text()), true
+}
+
+// dipMenu runs an interactive menu on the console for changing the DIP
+// switches.
+//
+// Emulation is suspended while the menu is open.
+func (m *Machine) dipMenu() {
+	defer m.pc.reset()
+
+	c := m.con
+	fmt.Fprintf(c.out, "DIP switches: %s\n", m.dips)
+	fmt.Fprintln(c.out, "Enter NAME=VALUE to change a switch, or nothing to return to the game.")
+
+	for {
+		line, ok := c.prompt("dip> ")
+		if !ok || line == "" {
+			return
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			fmt.Fprintln(c.out, "expected NAME=VALUE, e.g. lives=5")
+			continue
+		}
+
+		d := m.dips
+		if err := d.Set(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])); err != nil {
+			fmt.Fprintln(c.out, err)
+			continue
+		}
+		if err := m.SetDIP(d); err != nil {
+			fmt.Fprintln(c.out, err)
+			continue
+		}
+
+		fmt.Fprintf(c.out, "DIP switches: %s\n", m.dips)
+	}
+}
+
+// mixerMenu runs an interactive menu on the console for changing the volumes of
+// the mixer.
+//
+// Emulation is suspended while the menu is open.
+func (m *Machine) mixerMenu() {
+	defer m.pc.reset()
+
+	c := m.con
+	fmt.Fprintf(c.out, "Mixer: %s\n", m.mx)
+	fmt.Fprintln(c.out, "Enter NAME=VALUE to set a volume (0 to 2) or to mute (off) or unmute (on) a channel, or nothing to return to the game.")
+
+	for {
+		line, ok := c.prompt("mixer> ")
+		if !ok || line == "" {
+			return
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			fmt.Fprintln(c.out, "expected NAME=VALUE, e.g. volume=0.5 or ufo=off")
+			continue
+		}
+
+		if err := m.mx.Set(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])); err != nil {
+			fmt.Fprintln(c.out, err)
+			continue
+		}
+
+		fmt.Fprintf(c.out, "Mixer: %s\n", m.mx)
+	}
+}
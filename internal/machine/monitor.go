@@ -0,0 +1,396 @@
+package machine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danmrichards/disassemble8080/pkg/dasm"
+)
+
+const (
+	// The number of instructions listed, and how many of them precede the
+	// program counter, when disassembling around it.
+	listLen    = 10
+	listBefore = 4
+
+	// The number of bytes shown by a hex dump, and on each line of it.
+	dumpLen  = 64
+	dumpLine = 16
+)
+
+// monitorHelp describes the monitor commands.
+const monitorHelp = `Commands (addresses and bytes in hex):
+  c               continue
+  s [N]           step N instructions (default 1)
+  n               step over a call
+  f               continue to the start of the next frame
+  r               show the registers and flags
+  l [ADDR] [N]    disassemble N instructions from ADDR (default around PC)
+  m ADDR [LEN]    hex dump LEN bytes of memory from ADDR (default 64)
+  e ADDR BYTE...  write bytes to memory from ADDR
+  b [ADDR]        set a breakpoint at ADDR, or list the breakpoints
+  d ADDR          delete the breakpoint at ADDR
+  h               show this help
+Enter nothing to repeat the last command.`
+
+// monitor holds the state of the debug monitor: the breakpoints, and what
+// should return control to the monitor once emulation continues.
+type monitor struct {
+	// The addresses of the breakpoints.
+	bps map[uint16]bool
+
+	// Whether to break once the given number of instructions have been
+	// stepped.
+	stepping bool
+	steps    int
+
+	// Whether to break on returning from a call that is being stepped over,
+	// at the given address with the stack unwound to the given pointer.
+	over   bool
+	overPC uint16
+	overSP uint16
+
+	// Whether to break at the start of the next frame.
+	frame bool
+
+	// Set while the instruction the monitor was left at has not yet run, so
+	// that continuing from a breakpoint does not break on it again.
+	skip bool
+
+	// The last command entered.
+	last string
+}
+
+// WithBreakpoints sets breakpoints at the given addresses, opening the monitor
+// when the CPU reaches any of them.
+func WithBreakpoints(addrs ...uint16) Option {
+	return func(m *Machine) {
+		for _, a := range addrs {
+			m.mon.set(a)
+		}
+	}
+}
+
+// armed returns true if the monitor may need to break before an instruction.
+func (mo *monitor) armed() bool {
+	return len(mo.bps) > 0 || mo.stepping || mo.over
+}
+
+// set sets a breakpoint at the given address.
+func (mo *monitor) set(addr uint16) {
+	if mo.bps == nil {
+		mo.bps = make(map[uint16]bool)
+	}
+	mo.bps[addr] = true
+}
+
+// stepped records that an instruction has run.
+func (mo *monitor) stepped() {
+	mo.skip = false
+	if mo.steps > 0 {
+		mo.steps--
+	}
+}
+
+// debugBreak opens the monitor if emulation should break before the next
+// instruction runs.
+func (m *Machine) debugBreak() {
+	mo := &m.mon
+	pc := m.c.PC()
+
+	switch {
+	case mo.stepping && mo.steps == 0:
+		m.monitor("")
+	case mo.over && pc == mo.overPC && m.c.State().SP >= mo.overSP:
+		m.monitor("")
+	case mo.bps[pc] && !mo.skip:
+		m.monitor(fmt.Sprintf("breakpoint at %04x", pc))
+	}
+}
+
+// monitor runs the debug monitor on the console, showing why emulation
+// stopped, if given, until a command continues emulation.
+//
+// Emulation is suspended while the monitor is open.
+func (m *Machine) monitor(why string) {
+	defer m.pc.reset()
+
+	mo := &m.mon
+	mo.stepping, mo.over, mo.frame = false, false, false
+
+	c := m.con
+	if why != "" {
+		fmt.Fprintln(c.out, why)
+	}
+	m.showState()
+
+	for {
+		line, ok := c.prompt("mon> ")
+		if !ok {
+			return
+		}
+		if line == "" {
+			line = mo.last
+		}
+		if line == "" {
+			continue
+		}
+		mo.last = line
+
+		cont, err := m.monitorCommand(strings.Fields(line))
+		if err != nil {
+			fmt.Fprintln(c.out, err)
+			continue
+		}
+		if cont {
+			mo.skip = true
+			return
+		}
+	}
+}
+
+// monitorCommand performs the given monitor command. The returned bool is true
+// if the command continues emulation.
+func (m *Machine) monitorCommand(args []string) (bool, error) {
+	mo := &m.mon
+	c := m.con
+	cmd, args := args[0], args[1:]
+
+	switch cmd {
+	case "c":
+		return true, nil
+	case "s":
+		n, err := parseCount(args, 1)
+		if err != nil {
+			return false, err
+		}
+		mo.stepping, mo.steps = true, n
+		return true, nil
+	case "n":
+		s := m.c.State()
+		if !isCall(m.mem.Read(s.PC)) {
+			mo.stepping, mo.steps = true, 1
+			return true, nil
+		}
+		_, n := m.disassemble(s.PC)
+		mo.over, mo.overPC, mo.overSP = true, s.PC+n, s.SP
+		return true, nil
+	case "f":
+		mo.frame = true
+		return true, nil
+	case "r":
+		m.showState()
+	case "l":
+		addr, n := m.listStart(m.c.PC()), listLen
+		if len(args) > 0 {
+			a, err := parseAddr(args[0])
+			if err != nil {
+				return false, err
+			}
+			addr = a
+		}
+		if len(args) > 1 {
+			var err error
+			if n, err = parseCount(args[1:], listLen); err != nil {
+				return false, err
+			}
+		}
+		m.list(addr, n)
+	case "m":
+		if len(args) == 0 {
+			return false, errors.New("expected m ADDR [LEN]")
+		}
+		addr, err := parseAddr(args[0])
+		if err != nil {
+			return false, err
+		}
+		n, err := parseCount(args[1:], dumpLen)
+		if err != nil {
+			return false, err
+		}
+		m.dump(addr, n)
+	case "e":
+		if len(args) < 2 {
+			return false, errors.New("expected e ADDR BYTE...")
+		}
+		addr, err := parseAddr(args[0])
+		if err != nil {
+			return false, err
+		}
+		for i, a := range args[1:] {
+			v, err := strconv.ParseUint(a, 16, 8)
+			if err != nil {
+				return false, fmt.Errorf("invalid byte %q", a)
+			}
+			at := addr + uint16(i)
+			m.mem.Write(at, byte(v))
+			if m.mem.Read(at) != byte(v) {
+				return false, fmt.Errorf("could not write to %04x: read only", at)
+			}
+		}
+		m.dump(addr, len(args)-1)
+	case "b":
+		if len(args) == 0 {
+			m.listBreakpoints()
+			break
+		}
+		addr, err := parseAddr(args[0])
+		if err != nil {
+			return false, err
+		}
+		mo.set(addr)
+	case "d":
+		if len(args) == 0 {
+			return false, errors.New("expected d ADDR")
+		}
+		addr, err := parseAddr(args[0])
+		if err != nil {
+			return false, err
+		}
+		if !mo.bps[addr] {
+			return false, fmt.Errorf("no breakpoint at %04x", addr)
+		}
+		delete(mo.bps, addr)
+	case "h", "?":
+		fmt.Fprintln(c.out, monitorHelp)
+	default:
+		return false, fmt.Errorf("unknown command %q: enter h for help", cmd)
+	}
+
+	return false, nil
+}
+
+// showState writes the registers, flags and next instruction to the console.
+func (m *Machine) showState() {
+	s := m.c.State()
+	fmt.Fprintf(m.con.out, "%s CYCLE=%d\n", s, m.fc)
+	m.list(s.PC, 1)
+}
+
+// list writes the disassembly of n instructions from addr to the console,
+// marking the program counter and breakpoints.
+func (m *Machine) list(addr uint16, n int) {
+	pc := m.c.PC()
+	for i := 0; i < n; i++ {
+		mark := "  "
+		switch {
+		case addr == pc:
+			mark = "=>"
+		case m.mon.bps[addr]:
+			mark = " *"
+		}
+
+		asm, l := m.disassemble(addr)
+		fmt.Fprintf(m.con.out, "%s %s\n", mark, asm)
+		addr += l
+	}
+}
+
+// listStart returns the address to disassemble from to list up to listBefore
+// instructions before addr.
+//
+// 8080 instructions vary in length, so the start is found by disassembling
+// forward from successively later addresses until one lands on addr.
+func (m *Machine) listStart(addr uint16) uint16 {
+	for back := 3 * listBefore; back > 0; back-- {
+		if back > int(addr) {
+			continue
+		}
+
+		a, n := int(addr)-back, 0
+		for a < int(addr) {
+			_, l := m.disassemble(uint16(a))
+			a += int(l)
+			n++
+		}
+		if a == int(addr) && n <= listBefore {
+			return addr - uint16(back)
+		}
+	}
+
+	return addr
+}
+
+// disassemble returns the disassembly of the instruction at addr, and its
+// length.
+func (m *Machine) disassemble(addr uint16) (string, uint16) {
+	var b [3]byte
+	for i := range b {
+		b[i] = m.mem.Read(addr + uint16(i))
+	}
+
+	// The disassembler prefixes the address it was given.
+	asm, n := dasm.Disassemble(b[:], 0)
+	asm = strings.TrimPrefix(asm, "0000 ")
+
+	return fmt.Sprintf("%04x  %-8s  %s", addr, fmt.Sprintf("% x", b[:n]), asm), uint16(n)
+}
+
+// dump writes a hex dump of n bytes of memory from addr to the console.
+func (m *Machine) dump(addr uint16, n int) {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		if i%dumpLine == 0 {
+			if i > 0 {
+				sb.WriteByte('\n')
+			}
+			fmt.Fprintf(&sb, "%04x ", addr+uint16(i))
+		}
+		fmt.Fprintf(&sb, " %02x", m.mem.Read(addr+uint16(i)))
+	}
+	fmt.Fprintln(m.con.out, sb.String())
+}
+
+// listBreakpoints writes the breakpoints to the console, in address order.
+func (m *Machine) listBreakpoints() {
+	if len(m.mon.bps) == 0 {
+		fmt.Fprintln(m.con.out, "no breakpoints")
+		return
+	}
+
+	addrs := make([]int, 0, len(m.mon.bps))
+	for a := range m.mon.bps {
+		addrs = append(addrs, int(a))
+	}
+	sort.Ints(addrs)
+
+	for _, a := range addrs {
+		m.list(uint16(a), 1)
+	}
+}
+
+// isCall returns true if the given opcode calls a subroutine: CALL, a
+// conditional call or RST.
+func isCall(opc byte) bool {
+	return opc == 0xcd || opc&0xc7 == 0xc4 || opc&0xc7 == 0xc7
+}
+
+// parseAddr parses a hex address, with an optional 0x or $ prefix.
+func parseAddr(s string) (uint16, error) {
+	t := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(s), "0x"), "$")
+
+	a, err := strconv.ParseUint(t, 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid address %q", s)
+	}
+
+	return uint16(a), nil
+}
+
+// parseCount parses the optional decimal count in args, returning def if
+// there is none.
+func parseCount(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid count %q: must be a positive number", args[0])
+	}
+
+	return n, nil
+}
@@ -0,0 +1,240 @@
+package machine
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/danmrichards/go-invaders/internal/memory"
+)
+
+// monitorProgram is a program for testing the monitor, keyed by address: a
+// call to a short subroutine, followed by an endless loop.
+var monitorProgram = map[uint16][]byte{
+	0x0100: {
+		0xcd, 0x10, 0x01, // CALL $0110
+		0x00,             // NOP
+		0x00,             // NOP
+		0xc3, 0x05, 0x01, // JMP $0105
+	},
+	0x0110: {
+		0x3e, 0x42, // MVI A,#$42
+		0x3c, // INR A
+		0xc9, // RET
+	},
+}
+
+// pcPattern matches the program counter shown by the monitor each time it
+// opens.
+var pcPattern = regexp.MustCompile(`PC=([0-9a-f]{4})`)
+
+// runMonitor opens the monitor at the start of monitorProgram, on a machine
+// with the mapped memory of the board, with each of the given commands
+// entered in turn. Once the commands run out the machine runs on for a few
+// instructions.
+//
+// It returns the console output, the program counter each time the monitor
+// opened and the machine.
+func runMonitor(t *testing.T, cmds ...string) (string, []string, *Machine) {
+	t.Helper()
+
+	mem := memory.NewMapped()
+	for addr, code := range monitorProgram {
+		copy(mem.ReadAll()[addr:], code)
+	}
+
+	var out bytes.Buffer
+	m, err := New(mem, WithConsole(strings.NewReader(strings.Join(cmds, "\n")+"\n"), &out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.c.SetState(cpuState{Flags: 0x02, SP: 0x2400, PC: 0x0100})
+
+	m.monitor("")
+	if err = m.runUntil(m.fc + 100); err != nil {
+		t.Fatal(err)
+	}
+
+	var pcs []string
+	for _, sm := range pcPattern.FindAllStringSubmatch(out.String(), -1) {
+		pcs = append(pcs, sm[1])
+	}
+
+	return out.String(), pcs, m
+}
+
+func TestMonitorStep(t *testing.T) {
+	tests := []struct {
+		name string
+		cmds []string
+		pcs  []string
+	}{
+		{"step", []string{"s", "s", "s"}, []string{"0100", "0110", "0112", "0113"}},
+		{"step count", []string{"s 2", "s 2"}, []string{"0100", "0112", "0103"}},
+		{"repeat", []string{"s", ""}, []string{"0100", "0110", "0112"}},
+		{"step over call", []string{"n"}, []string{"0100", "0103"}},
+		{"step over other", []string{"n", "n", "n"}, []string{"0100", "0103", "0104", "0105"}},
+		{"step over in call", []string{"s", "n", "n", "n"}, []string{"0100", "0110", "0112", "0113", "0103"}},
+		{"step over to breakpoint", []string{"b 0112", "n"}, []string{"0100", "0112"}},
+		{"continue", []string{"c"}, []string{"0100"}},
+		{"invalid count", []string{"s 0", "s x", "s"}, []string{"0100", "0110"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, pcs, _ := runMonitor(t, tt.cmds...)
+			if !reflect.DeepEqual(pcs, tt.pcs) {
+				t.Errorf("monitor opened at %v, want %v\n%s", pcs, tt.pcs, out)
+			}
+		})
+	}
+}
+
+func TestMonitorBreakpoints(t *testing.T) {
+	out, pcs, m := runMonitor(t,
+		"b", "b 0x0112", "b $110", "b", "c", "d 0110", "b", "d 110", "d", "b zz", "c",
+	)
+
+	if want := []string{"0100", "0110", "0112"}; !reflect.DeepEqual(pcs, want) {
+		t.Errorf("monitor opened at %v, want %v\n%s", pcs, want, out)
+	}
+
+	// The breakpoints are listed in address order, and again once one is
+	// deleted.
+	for _, want := range []string{
+		"no breakpoints\n",
+		" * 0110  3e 42     ",
+		" * 0112  3c        ",
+		"breakpoint at 0110\n",
+		"breakpoint at 0112\n",
+		`=> 0112  3c        `,
+		"no breakpoint at 0110\n",
+		"expected d ADDR\n",
+		`invalid address "zz"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, " * 0110"); n != 1 {
+		t.Errorf("breakpoint at 0110 listed %d times, want once before it was deleted:\n%s", n, out)
+	}
+
+	if want := map[uint16]bool{0x0112: true}; !reflect.DeepEqual(m.mon.bps, want) {
+		t.Errorf("breakpoints = %v, want %v", m.mon.bps, want)
+	}
+}
+
+func TestMonitorWrite(t *testing.T) {
+	out, _, m := runMonitor(t, "e 2000 12 34", "e 0100 00", "e 1fff ff", "e 2002 100", "e 2002")
+
+	for _, want := range []string{
+		"2000  12 34\n",
+		"could not write to 0100: read only\n",
+		"could not write to 1fff: read only\n",
+		`invalid byte "100"`,
+		"expected e ADDR BYTE...\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	if got := []byte{m.mem.Read(0x2000), m.mem.Read(0x2001)}; !bytes.Equal(got, []byte{0x12, 0x34}) {
+		t.Errorf("RAM = % x, want 12 34", got)
+	}
+	if got := m.mem.Read(0x0100); got != 0xcd {
+		t.Errorf("ROM at 0100 = %02x, want it unchanged at cd", got)
+	}
+}
+
+func TestMonitorUnknownCommand(t *testing.T) {
+	out, _, _ := runMonitor(t, "x")
+	if !strings.Contains(out, `unknown command "x"`) {
+		t.Errorf("output does not report the unknown command:\n%s", out)
+	}
+}
+
+func TestListStart(t *testing.T) {
+	m := newTestMachine(t)
+
+	tests := []struct {
+		addr, want uint16
+	}{
+		// There is nothing before the start of memory.
+		{0x0000, 0x0000},
+
+		// No instruction ends at $0001, inside the JMP at $0000.
+		{0x0001, 0x0001},
+
+		// The JMP at $0000 is the only instruction before $0003.
+		{0x0003, 0x0000},
+
+		// Up to listBefore instructions are listed before the address: the
+		// NOPs at $0004 to $0007, not the JMP.
+		{0x0008, 0x0004},
+
+		// The NOPs before LXI SP and EI.
+		{0x0024, 0x001e},
+	}
+	for _, tt := range tests {
+		if got := m.listStart(tt.addr); got != tt.want {
+			t.Errorf("listStart(%04x) = %04x, want %04x", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestIsCall(t *testing.T) {
+	tests := []struct {
+		opc  byte
+		want bool
+	}{
+		{0xcd, true},  // CALL
+		{0xc4, true},  // CNZ
+		{0xcc, true},  // CZ
+		{0xfc, true},  // CM
+		{0xc7, true},  // RST 0
+		{0xff, true},  // RST 7
+		{0xc3, false}, // JMP
+		{0xc2, false}, // JNZ
+		{0xc9, false}, // RET
+		{0xc0, false}, // RNZ
+		{0x00, false}, // NOP
+	}
+	for _, tt := range tests {
+		if got := isCall(tt.opc); got != tt.want {
+			t.Errorf("isCall(%02x) = %t, want %t", tt.opc, got, tt.want)
+		}
+	}
+}
+
+func TestParseAddr(t *testing.T) {
+	tests := []struct {
+		s    string
+		want uint16
+		ok   bool
+	}{
+		{"1a2b", 0x1a2b, true},
+		{"1A2B", 0x1a2b, true},
+		{"0x1a2b", 0x1a2b, true},
+		{"0X1A2B", 0x1a2b, true},
+		{"$ff", 0x00ff, true},
+		{"0", 0x0000, true},
+		{"ffff", 0xffff, true},
+		{"10000", 0, false},
+		{"zz", 0, false},
+		{"-1", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseAddr(tt.s)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseAddr(%q) error = %v, want ok %t", tt.s, err, tt.ok)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseAddr(%q) = %04x, want %04x", tt.s, got, tt.want)
+		}
+	}
+}
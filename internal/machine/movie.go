@@ -0,0 +1,237 @@
+package machine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"log"
+)
+
+// The version of the movie format. This must be incremented whenever the
+// format changes.
+const movieVersion uint16 = 1
+
+// movieMagic identifies a movie file.
+var movieMagic = [4]byte{'G', 'I', 'M', 'V'}
+
+type (
+	// movieHeader is the header of a movie file.
+	//
+	// In the movie file it is followed by a movieFrame for every frame
+	// emulated since power on.
+	movieHeader struct {
+		Magic   [4]byte
+		Version uint16
+
+		// CRC32 checksums of the four 2K ROM parts.
+		ROM [4]uint32
+
+		// The DIP switch bits of input port 2.
+		DIP byte
+	}
+
+	// movieFrame is the input latched for a single frame, and the hash of the
+	// RAM once the frame was emulated.
+	movieFrame struct {
+		In1  byte
+		In2  byte
+		Hash uint32
+	}
+
+	// movie records or plays back the input latched for each frame.
+	movie struct {
+		// Exactly one of w or r is set, depending on whether the movie is
+		// being recorded or played back.
+		w *bufio.Writer
+		r *bufio.Reader
+
+		// The number of frames recorded or played back.
+		frame int
+
+		// The frame currently being played back.
+		cur movieFrame
+
+		// The first frame on which playback diverged from the recording, or
+		// -1 if it has not.
+		desync int
+	}
+)
+
+// errMovieStarted is returned when a movie is started after the first frame
+// has been emulated: a movie holds the input of every frame since power on, so
+// could not be played back in step.
+var errMovieStarted = errors.New("a movie must be started before the first frame is emulated")
+
+// RecordMovie starts recording the input for every emulated frame to w.
+//
+// Recording must be started before the first frame is emulated, otherwise an
+// error is returned, and is finished by StopMovie.
+func (m *Machine) RecordMovie(w io.Writer) error {
+	if m.ran {
+		return errMovieStarted
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if err := binary.Write(bw, binary.LittleEndian, m.movieHeader()); err != nil {
+		return err
+	}
+
+	m.mv = &movie{
+		w:      bw,
+		desync: -1,
+	}
+
+	return nil
+}
+
+// PlayMovie starts playing back the movie read from r, in place of the input
+// frontend.
+//
+// Playback must be started before the first frame is emulated, otherwise an
+// error is returned. An error is also returned if the movie was recorded
+// against different ROMs. The DIP switches are set as they were when the movie
+// was recorded. Once the movie ends the input frontend takes over again.
+func (m *Machine) PlayMovie(r io.Reader) error {
+	if m.ran {
+		return errMovieStarted
+	}
+
+	br := bufio.NewReader(r)
+
+	var hdr movieHeader
+	if err := binary.Read(br, binary.LittleEndian, &hdr); err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+	if hdr.Magic != movieMagic {
+		return errors.New("not a movie")
+	}
+	if hdr.Version != movieVersion {
+		return fmt.Errorf("unsupported movie version %d", hdr.Version)
+	}
+	if want := m.movieHeader(); hdr.ROM != want.ROM {
+		return errors.New("movie was recorded with different ROMs")
+	}
+
+	// Play back with the DIP switches the movie was recorded with.
+	m.dips = dipFromBits(hdr.DIP)
+
+	m.mv = &movie{
+		r:      br,
+		desync: -1,
+	}
+
+	return nil
+}
+
+// StopMovie stops recording or playing back a movie.
+func (m *Machine) StopMovie() error {
+	mv := m.mv
+	m.mv = nil
+
+	if mv != nil && mv.w != nil {
+		return mv.w.Flush()
+	}
+
+	return nil
+}
+
+// Desync returns the first frame on which movie playback diverged from the
+// recording. The returned bool is false if playback has not diverged.
+func (m *Machine) Desync() (int, bool) {
+	if m.mv == nil || m.mv.desync < 0 {
+		return 0, false
+	}
+
+	return m.mv.desync, true
+}
+
+// movieActive returns true if a movie is being recorded or played back. Once
+// playback has finished the input frontend has taken over, so the movie is no
+// longer active.
+func (m *Machine) movieActive() bool {
+	return m.mv != nil && (m.mv.w != nil || m.mv.r != nil)
+}
+
+// movieHeader returns the movie header for the current machine.
+func (m *Machine) movieHeader() movieHeader {
+	hdr := movieHeader{
+		Magic:   movieMagic,
+		Version: movieVersion,
+		DIP:     m.dip(),
+	}
+
+	// Checksum each of the 2K ROM parts.
+	for i := range hdr.ROM {
+		part := make([]byte, 0x800)
+		for j := range part {
+			part[j] = m.mem.Read(uint16(i*0x800 + j))
+		}
+		hdr.ROM[i] = crc32.ChecksumIEEE(part)
+	}
+
+	return hdr
+}
+
+// movieInput replaces the latched input with the input for the coming frame
+// when playing back a movie.
+//
+// Once the end of the movie is reached the latched input is left as read from
+// the input frontend.
+func (m *Machine) movieInput() {
+	mv := m.mv
+	if mv.r == nil {
+		return
+	}
+
+	if err := binary.Read(mv.r, binary.LittleEndian, &mv.cur); err != nil {
+		if !errors.Is(err, io.EOF) {
+			log.Printf("read movie frame %d: %v", mv.frame, err)
+		}
+		log.Printf("movie playback finished after %d frames", mv.frame)
+
+		// Hand back to the input frontend.
+		mv.r = nil
+		return
+	}
+
+	m.in1, m.in2 = mv.cur.In1, mv.cur.In2
+}
+
+// movieFrame records the input and RAM hash of the frame just emulated, or
+// checks the RAM hash against the recording when playing back a movie.
+func (m *Machine) movieFrame() error {
+	mv := m.mv
+	h := m.ramHash()
+
+	switch {
+	case mv.w != nil:
+		f := movieFrame{
+			In1:  m.in1,
+			In2:  m.in2,
+			Hash: h,
+		}
+		if err := binary.Write(mv.w, binary.LittleEndian, f); err != nil {
+			return fmt.Errorf("write movie frame %d: %w", mv.frame, err)
+		}
+	case mv.r != nil && mv.cur.Hash != h && mv.desync < 0:
+		mv.desync = mv.frame
+		log.Printf("movie desync at frame %d", mv.frame)
+	}
+
+	mv.frame++
+
+	return nil
+}
+
+// ramHash returns the FNV-1a hash of the RAM, including video RAM.
+func (m *Machine) ramHash() uint32 {
+	h := fnv.New32a()
+	m.DumpRAM(h) //nolint:errcheck
+
+	return h.Sum32()
+}
@@ -0,0 +1,100 @@
+package machine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMoviePlayback(t *testing.T) {
+	const frames = 180
+
+	dir, err := ioutil.TempDir("", "movie")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Rewinding and loading a state are refused while recording, so they
+	// must not stop the movie playing back exactly.
+	in := &testInput{
+		pressed: func(f int, b Button) bool {
+			switch b {
+			case ButtonCoin:
+				return f%7 == 0
+			case ButtonP1Left:
+				return f%5 < 2
+			case ButtonP1Shoot:
+				return f%11 == 3
+			case ButtonRewind:
+				return f >= 60 && f < 90
+			case ButtonLoadState:
+				return f == 120
+			}
+			return false
+		},
+	}
+	rec := newTestMachine(t, WithInput(in), WithRewind(1, 100), WithStateDir(dir))
+	if err = rec.SaveSlot(0); err != nil {
+		t.Fatal(err)
+	}
+
+	var mv bytes.Buffer
+	if err = rec.RecordMovie(&mv); err != nil {
+		t.Fatal(err)
+	}
+	for ; in.frame < frames; in.frame++ {
+		rec.hotkeys()
+		if err = rec.frame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = rec.StopMovie(); err != nil {
+		t.Fatal(err)
+	}
+
+	play := newTestMachine(t, WithRewind(1, 100))
+	if err = play.PlayMovie(bytes.NewReader(mv.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < frames; i++ {
+		if err = play.frame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if f, ok := play.Desync(); ok {
+		t.Errorf("playback desynced at frame %d", f)
+	}
+	if want, got := dumpRAM(t, rec), dumpRAM(t, play); !bytes.Equal(got, want) {
+		t.Error("RAM after playback differs from RAM after recording")
+	}
+}
+
+func TestMovieStartedLate(t *testing.T) {
+	m := newTestMachine(t)
+
+	var mv bytes.Buffer
+	if err := m.RecordMovie(&mv); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.StopMovie(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once a frame has been emulated a movie can neither be recorded nor
+	// played back in step with the machine.
+	if err := m.frame(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RecordMovie(ioutil.Discard); err == nil {
+		t.Error("expected an error recording a movie after the first frame")
+	}
+	if err := m.PlayMovie(bytes.NewReader(mv.Bytes())); err == nil {
+		t.Error("expected an error playing back a movie after the first frame")
+	}
+	if m.movieActive() {
+		t.Error("movie started after the first frame")
+	}
+}
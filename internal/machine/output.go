@@ -4,6 +4,10 @@ import (
 	"fmt"
 )
 
+// The amplifier enable bit of sound port 3. All sound is muted while it is
+// clear.
+const ampEnable = 0x01 << 5
+
 // output handles output operations for the given port.
 func (m *Machine) output(port byte) {
 	if m.debug {
@@ -15,50 +19,66 @@ func (m *Machine) output(port byte) {
 		// Set the shift register offset.
 		m.so = uint16(m.c.Accumulator()) & 0x07
 	case 0x03:
-		m.playSound(m.c.Accumulator(), 1)
+		m.snd1 = m.c.Accumulator()
+		m.sound(port, m.snd1)
 	case 0x04:
 		// Set the shift data.
 		m.sd = uint16(m.c.Accumulator())<<8 | m.sd>>8
 	case 0x05:
-		m.playSound(m.c.Accumulator(), 2)
+		m.snd2 = m.c.Accumulator()
+		m.sound(port, m.snd2)
 	case 0x06:
 		m.wd = m.c.Accumulator()
 	}
 }
 
-// playSound plays the sound indicated by the given data and sound bank.
-func (m *Machine) playSound(data byte, bank int) {
-	// Returns true if the given bit is set.
-	bit := func(b byte, pos int) bool {
-		return (b & (0x01 << pos)) != 0
+// sound passes the value written to the given sound port to the audio sink,
+// and records it if a video is being recorded.
+func (m *Machine) sound(port, data byte) {
+	m.a.Out(port, data, int(m.fc))
+
+	if m.rec != nil {
+		m.rec.Sound(port, data, int(m.fc))
 	}
+}
 
-	switch {
-	case bank == 1 && data != m.snd1:
-		if bit(data, 0) && !bit(m.snd1, 0) {
-			m.p.Play("0.wav")
-		} else if bit(data, 1) && !bit(m.snd1, 1) {
-			m.p.Play("1.wav")
-		} else if bit(data, 2) && !bit(m.snd1, 2) {
-			m.p.Play("2.wav")
-		} else if bit(data, 3) && !bit(m.snd1, 3) {
-			m.p.Play("3.wav")
-		}
+// resound sends the sound latches to the audio sink again, and to the
+// recorder if a video is being recorded, after the machine state has been
+// replaced. The latches are cleared first so that every sound of the new state
+// starts afresh, and every sound that is no longer playing stops.
+func (m *Machine) resound() {
+	m.sound(0x03, 0)
+	m.sound(0x05, 0)
+	m.sound(0x03, m.snd1)
+	m.sound(0x05, m.snd2)
+
+	if m.rw != nil {
+		m.rw.muted = false
+	}
+}
 
-		m.snd1 = data
-	case bank == 2 && data != m.snd2:
-		if bit(data, 0) && !bit(m.snd2, 0) {
-			m.p.Play("4.wav")
-		} else if bit(data, 1) && !bit(m.snd2, 1) {
-			m.p.Play("5.wav")
-		} else if bit(data, 2) && !bit(m.snd2, 2) {
-			m.p.Play("6.wav")
-		} else if bit(data, 3) && !bit(m.snd2, 3) {
-			m.p.Play("7.wav")
-		} else if bit(data, 4) && !bit(m.snd2, 4) {
-			m.p.Play("8.wav")
-		}
+// rewindSound sends the sound latches restored by rewinding to the audio sink,
+// given the latches before the rewind.
+//
+// The sink is muted, by disabling the amplifier, for as long as rewinding
+// continues. A latch is only sent if it changed, so that the sounds playing
+// are not restarted on every rewound frame.
+func (m *Machine) rewindSound(snd1, snd2 byte) {
+	if s := m.snd1 &^ ampEnable; !m.rw.muted || s != snd1&^ampEnable {
+		m.sound(0x03, s)
+	}
+	if m.snd2 != snd2 {
+		m.sound(0x05, m.snd2)
+	}
+	m.rw.muted = true
+}
 
-		m.snd2 = data
+// unmute restores the amplifier once rewinding has finished.
+func (m *Machine) unmute() {
+	if m.rw == nil || !m.rw.muted {
+		return
 	}
+
+	m.rw.muted = false
+	m.sound(0x03, m.snd1)
 }
@@ -0,0 +1,114 @@
+package machine
+
+import (
+	"time"
+)
+
+const (
+	// The frame period of the original machine at normal speed.
+	framePeriod = time.Duration(cyclesPerFrame) * time.Second / clockSpeed
+
+	// The most the emulation may fall behind real time before the lost time
+	// is dropped, rather than caught up by emulating frames back to back.
+	maxLag = 100 * time.Millisecond
+)
+
+// speeds are the selectable emulation speed multipliers, slowest first.
+var speeds = []float64{0.25, 0.5, 1, 2, 4, 8}
+
+// normalSpeed is the index of normal speed in speeds.
+const normalSpeed = 2
+
+// pacer paces emulation in real time, independently of the refresh rate of the
+// video frontend.
+//
+// Real time elapsed is accumulated, scaled by the emulation speed, and a frame
+// is due for every frame period accumulated. At normal speed frames are due
+// whenever the clock says so instead, if there is one.
+type pacer struct {
+	// The clock pacing emulation at normal speed, nil to pace by wall time.
+	clk Clock
+
+	// The index of the selected speed.
+	speed int
+
+	// Flags for pause and for a single frame to be emulated while paused.
+	paused  bool
+	advance bool
+
+	// The time of the last call to due, and the scaled time accumulated but
+	// not yet emulated.
+	last time.Time
+	acc  time.Duration
+}
+
+// reset discards any accumulated time.
+func (p *pacer) reset() {
+	p.last = time.Now()
+	p.acc = 0
+}
+
+// due returns the number of frames that are due to be emulated now.
+func (p *pacer) due() int {
+	now := time.Now()
+	elapsed := now.Sub(p.last)
+	p.last = now
+
+	if p.paused {
+		p.acc = 0
+		if p.advance {
+			p.advance = false
+			return 1
+		}
+		return 0
+	}
+
+	if p.clocked() {
+		p.acc = 0
+		return p.clk.Due()
+	}
+
+	p.acc += time.Duration(float64(elapsed) * speeds[p.speed])
+
+	// Drop time that cannot be caught up, such as when the process was
+	// suspended.
+	if lag := time.Duration(float64(maxLag) * speeds[p.speed]); p.acc > lag {
+		p.acc = lag
+	}
+
+	n := int(p.acc / framePeriod)
+	p.acc -= time.Duration(n) * framePeriod
+
+	return n
+}
+
+// wait returns how long to wait until the next frame is due.
+func (p *pacer) wait() time.Duration {
+	if p.paused {
+		return framePeriod
+	}
+	if p.clocked() {
+		return p.clk.Wait()
+	}
+
+	return time.Duration(float64(framePeriod-p.acc) / speeds[p.speed])
+}
+
+// clocked returns true if emulation is currently paced by the clock.
+func (p *pacer) clocked() bool {
+	return p.clk != nil && p.speed == normalSpeed
+}
+
+// faster selects the next fastest speed.
+func (p *pacer) faster() {
+	if p.speed < len(speeds)-1 {
+		p.speed++
+	}
+}
+
+// slower selects the next slowest speed.
+func (p *pacer) slower() {
+	if p.speed > 0 {
+		p.speed--
+	}
+}
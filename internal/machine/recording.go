@@ -0,0 +1,68 @@
+package machine
+
+import (
+	"errors"
+)
+
+// WithVideoRecorder sets the function that starts a new video recording when
+// the record hotkey is pressed.
+func WithVideoRecorder(start func() (Recorder, error)) Option {
+	return func(m *Machine) {
+		m.newRec = start
+	}
+}
+
+// StartRecording starts recording video to r, stopping any recording already
+// in progress.
+func (m *Machine) StartRecording(r Recorder) error {
+	if err := m.StopRecording(); err != nil {
+		return err
+	}
+	m.startRecording(r)
+
+	return nil
+}
+
+// StopRecording stops and finishes the recording in progress, if any.
+func (m *Machine) StopRecording() error {
+	if m.rec == nil {
+		return nil
+	}
+
+	r := m.rec
+	m.rec = nil
+
+	return r.Close()
+}
+
+// Recording returns true if a video is being recorded.
+func (m *Machine) Recording() bool {
+	return m.rec != nil
+}
+
+// toggleRecording stops the recording in progress or, if there is none,
+// starts a new one.
+func (m *Machine) toggleRecording() error {
+	if m.rec != nil {
+		return m.StopRecording()
+	}
+	if m.newRec == nil {
+		return errors.New("video recording is not available")
+	}
+
+	r, err := m.newRec()
+	if err != nil {
+		return err
+	}
+	m.startRecording(r)
+
+	return nil
+}
+
+// startRecording starts recording video to r, from the current state of the
+// sound latches.
+func (m *Machine) startRecording(r Recorder) {
+	m.rec = r
+	m.rec.Sound(0x03, m.snd1, int(m.fc))
+	m.rec.Sound(0x05, m.snd2, int(m.fc))
+}
@@ -0,0 +1,187 @@
+package machine
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// rewinder keeps a history of machine snapshots, allowing gameplay to be
+// rewound.
+//
+// Only the most recent snapshot is stored in full. Every other snapshot is
+// stored as the XOR of itself and the snapshot that followed it, run-length
+// encoded. Most frames only touch the RAM, so the deltas are mostly zero and
+// compress to a handful of bytes.
+type rewinder struct {
+	// The number of frames between snapshots, and the number of frames
+	// emulated since the last snapshot.
+	every int
+	n     int
+
+	// The most recent snapshot.
+	cur []byte
+
+	// Ring buffer of deltas between consecutive snapshots, oldest first.
+	deltas [][]byte
+	head   int
+	size   int
+
+	// Whether the audio sink is muted while rewinding.
+	muted bool
+}
+
+// WithRewind enables rewinding, taking a snapshot every given number of frames
+// and keeping up to the given number of snapshots.
+func WithRewind(every, snapshots int) Option {
+	return func(m *Machine) {
+		if every < 1 || snapshots < 1 {
+			return
+		}
+
+		m.rw = &rewinder{
+			every:  every,
+			deltas: make([][]byte, snapshots),
+		}
+	}
+}
+
+// capture records a snapshot of the machine if one is due.
+func (m *Machine) capture() {
+	rw := m.rw
+
+	rw.n++
+	if rw.n < rw.every {
+		return
+	}
+	rw.n = 0
+
+	var buf bytes.Buffer
+	if err := m.SaveState(&buf); err != nil {
+		return
+	}
+	snap := buf.Bytes()
+
+	if rw.cur != nil && len(rw.cur) == len(snap) {
+		rw.push(xorRLE(rw.cur, snap))
+	}
+	rw.cur = snap
+}
+
+// rewind restores the machine to the previous snapshot.
+//
+// If frames have been emulated since the most recent snapshot, the first step
+// back restores that snapshot. Once the history is exhausted the machine is
+// held at the oldest snapshot.
+func (m *Machine) rewind() {
+	rw := m.rw
+	if rw.cur == nil {
+		return
+	}
+
+	if rw.n > 0 {
+		rw.n = 0
+	} else if d := rw.pop(); d != nil {
+		applyXORRLE(rw.cur, d)
+	}
+
+	// The snapshot was taken from this machine, so it cannot fail to load.
+	snd1, snd2 := m.snd1, m.snd2
+	m.restore(bytes.NewReader(rw.cur)) //nolint:errcheck
+	m.rewindSound(snd1, snd2)
+}
+
+// push adds a delta to the ring buffer, discarding the oldest delta if the
+// buffer is full.
+func (rw *rewinder) push(d []byte) {
+	i := (rw.head + rw.size) % len(rw.deltas)
+	rw.deltas[i] = d
+
+	if rw.size < len(rw.deltas) {
+		rw.size++
+	} else {
+		rw.head = (rw.head + 1) % len(rw.deltas)
+	}
+}
+
+// pop removes and returns the newest delta from the ring buffer, or nil if the
+// buffer is empty.
+func (rw *rewinder) pop() []byte {
+	if rw.size == 0 {
+		return nil
+	}
+
+	rw.size--
+	i := (rw.head + rw.size) % len(rw.deltas)
+	d := rw.deltas[i]
+	rw.deltas[i] = nil
+
+	return d
+}
+
+// xorRLE returns the run-length encoded XOR of a and b, which must be the same
+// length.
+//
+// The encoding is a sequence of runs, each being a uvarint count of zero bytes
+// to skip followed by a uvarint count of literal bytes and the literal bytes
+// themselves.
+func xorRLE(a, b []byte) []byte {
+	var (
+		out []byte
+		tmp [binary.MaxVarintLen64]byte
+	)
+
+	for i := 0; i < len(a); {
+		// Count the unchanged bytes.
+		z := i
+		for z < len(a) && a[z] == b[z] {
+			z++
+		}
+
+		// Count the changed bytes.
+		l := z
+		for l < len(a) && a[l] != b[l] {
+			l++
+		}
+
+		if l == z {
+			break
+		}
+
+		out = append(out, tmp[:binary.PutUvarint(tmp[:], uint64(z-i))]...)
+		out = append(out, tmp[:binary.PutUvarint(tmp[:], uint64(l-z))]...)
+		for j := z; j < l; j++ {
+			out = append(out, a[j]^b[j])
+		}
+
+		i = l
+	}
+
+	return out
+}
+
+// applyXORRLE applies the run-length encoded XOR delta d to buf in place.
+func applyXORRLE(buf, d []byte) {
+	r := bytes.NewReader(d)
+
+	var i int
+	for r.Len() > 0 {
+		z, err := binary.ReadUvarint(r)
+		if err != nil {
+			return
+		}
+		l, err := binary.ReadUvarint(r)
+		if err != nil {
+			return
+		}
+
+		i += int(z)
+		for j := 0; j < int(l); j++ {
+			x, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			buf[i] ^= x
+			i++
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package machine
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestXORRLE(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	// random returns n random bytes.
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		r.Read(b)
+		return b
+	}
+
+	// changed returns a copy of b with the bytes in the given ranges
+	// changed.
+	changed := func(b []byte, ranges ...[2]int) []byte {
+		c := append([]byte(nil), b...)
+		for _, rg := range ranges {
+			for i := rg[0]; i < rg[1]; i++ {
+				c[i] ^= 0x5a
+			}
+		}
+		return c
+	}
+
+	base := random(0x10000 + 0x400)
+	sparse := append([]byte(nil), base...)
+	for i := range sparse {
+		if r.Intn(10) == 0 {
+			sparse[i] = byte(r.Intn(256))
+		}
+	}
+
+	tests := []struct {
+		name string
+		a, b []byte
+	}{
+		{"empty", nil, nil},
+		{"identical", base, append([]byte(nil), base...)},
+		{"fully different", base, changed(base, [2]int{0, len(base)})},
+		{"random", random(1000), random(1000)},
+		{"sparse", base, sparse},
+		{"first and last", base, changed(base, [2]int{0, 1}, [2]int{len(base) - 1, len(base)})},
+
+		// Runs longer than fit in a single byte uvarint, and than fit in
+		// two.
+		{"long runs", base, changed(base, [2]int{200, 500}, [2]int{0x10000, 0x10000 + 0x300})},
+		{"long unchanged run", base, changed(base, [2]int{0x4100, 0x4101})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := xorRLE(tt.a, tt.b)
+			if bytes.Equal(tt.a, tt.b) && len(d) != 0 {
+				t.Errorf("delta of identical buffers is %d bytes, want 0", len(d))
+			}
+
+			// Applying the delta to either buffer gives the other.
+			got := append([]byte(nil), tt.b...)
+			applyXORRLE(got, d)
+			if !bytes.Equal(got, tt.a) {
+				t.Error("applying the delta to b does not give a")
+			}
+			got = append([]byte(nil), tt.a...)
+			applyXORRLE(got, d)
+			if !bytes.Equal(got, tt.b) {
+				t.Error("applying the delta to a does not give b")
+			}
+		})
+	}
+}
+
+func TestRewind(t *testing.T) {
+	const (
+		frames    = 20
+		snapshots = 5
+	)
+
+	in := &testInput{
+		pressed: func(f int, b Button) bool {
+			return b == ButtonRewind && f >= frames
+		},
+	}
+	m := newTestMachine(t, WithInput(in), WithRewind(1, snapshots))
+
+	// The state after each frame.
+	var states [][]byte
+	for ; in.frame < frames; in.frame++ {
+		if err := m.frame(); err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		if err := m.SaveState(&b); err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, b.Bytes())
+	}
+
+	// Each rewind steps back a frame, until the history is exhausted, after
+	// which the machine is held at the oldest snapshot.
+	for i := 1; i <= 2*snapshots; i++ {
+		if err := m.frame(); err != nil {
+			t.Fatal(err)
+		}
+
+		back := i
+		if back > snapshots {
+			back = snapshots
+		}
+		var b bytes.Buffer
+		if err := m.SaveState(&b); err != nil {
+			t.Fatal(err)
+		}
+		if want := states[frames-1-back]; !bytes.Equal(b.Bytes(), want) {
+			t.Errorf("rewind %d: state differs from the state %d frames back", i, back)
+		}
+	}
+}
+
+func TestRewindSound(t *testing.T) {
+	const frames = 5
+
+	in := &testInput{
+		pressed: func(f int, b Button) bool {
+			return b == ButtonRewind && f >= frames && f < frames+3
+		},
+	}
+	a := &testAudio{}
+	m := newTestMachine(t, WithInput(in), WithAudio(a), WithRewind(1, 10))
+
+	// The UFO sound plays with the amplifier on throughout, and the shot and
+	// a fleet note are added for the last two frames.
+	m.snd1 = ampEnable | 0x01
+	for ; in.frame < frames; in.frame++ {
+		if in.frame == 3 {
+			m.snd1, m.snd2 = ampEnable|0x03, 0x04
+		}
+		if err := m.frame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Rewinding three frames mutes the sink, sends the latches only when
+	// they change, and restores the amplifier once rewinding stops.
+	for ; in.frame < frames+4; in.frame++ {
+		if err := m.frame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := [][2]byte{
+		{0x03, 0x03},
+		{0x03, 0x01}, {0x05, 0x00},
+		{0x03, ampEnable | 0x01},
+	}
+	if !reflect.DeepEqual(a.out, want) {
+		t.Errorf("sound ports = %v, want %v", a.out, want)
+	}
+}
+
+func TestRewindEvery(t *testing.T) {
+	const (
+		every     = 3
+		snapshots = 3
+	)
+
+	tests := []struct {
+		name   string
+		frames int
+
+		// The frame whose state each rewind reaches, numbered from 1.
+		want []int
+	}{
+		// The first rewind goes back to the snapshot of frame 9, rather than
+		// skipping over it.
+		{"between snapshots", 10, []int{9, 6, 3, 3}},
+		{"at a snapshot", 9, []int{6, 3, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := &testInput{
+				pressed: func(f int, b Button) bool {
+					return b == ButtonRewind && f >= tt.frames
+				},
+			}
+			m := newTestMachine(t, WithInput(in), WithRewind(every, snapshots))
+
+			// The state after each frame.
+			var states [][]byte
+			for ; in.frame < tt.frames; in.frame++ {
+				if err := m.frame(); err != nil {
+					t.Fatal(err)
+				}
+				var b bytes.Buffer
+				if err := m.SaveState(&b); err != nil {
+					t.Fatal(err)
+				}
+				states = append(states, b.Bytes())
+			}
+
+			for i, f := range tt.want {
+				if err := m.frame(); err != nil {
+					t.Fatal(err)
+				}
+
+				var b bytes.Buffer
+				if err := m.SaveState(&b); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(b.Bytes(), states[f-1]) {
+					t.Errorf("rewind %d: state differs from the state after frame %d", i+1, f)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package machine
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithScreenshots sets the directory that screenshots are written to, and the
+// function that renders the screen for them.
+//
+// A nil render function writes the raw 1bpp screen, as returned by Image.
+func WithScreenshots(dir string, render func(s Screen) image.Image) Option {
+	return func(m *Machine) {
+		m.shdir = dir
+		m.shot = render
+	}
+}
+
+// Screenshot writes the current screen to a timestamped PNG in the screenshot
+// directory and returns the path of the file.
+func (m *Machine) Screenshot() (string, error) {
+	if err := os.MkdirAll(m.shdir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(
+		m.shdir,
+		"screenshot-"+time.Now().Format("20060102-150405.000")+".png",
+	)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	var img image.Image = m.Image()
+	if m.shot != nil {
+		img = m.shot(m)
+	}
+	if err = png.Encode(f, img); err != nil {
+		f.Close()
+		return "", err
+	}
+
+	return path, f.Close()
+}
@@ -0,0 +1,200 @@
+package machine
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// The version of the save state format. This must be incremented whenever
+	// the format changes.
+	stateVersion uint16 = 4
+
+	// The number of save state slots.
+	stateSlots = 10
+)
+
+// stateMagic identifies a save state file.
+var stateMagic = [4]byte{'G', 'I', 'S', 'S'}
+
+type (
+	// stateHeader is the header of a save state file.
+	stateHeader struct {
+		Magic   [4]byte
+		Version uint16
+	}
+
+	// machineState is a snapshot of the machine, excluding memory.
+	//
+	// In the save state file it is followed by the contents of memory.
+	machineState struct {
+		CPU cpuState
+
+		// Shift register offset and data.
+		ShiftOffset uint16
+		ShiftData   uint16
+
+		// Watchdog.
+		Watchdog byte
+
+		// Sound banks.
+		Sound1 byte
+		Sound2 byte
+
+		// The number of CPU cycles emulated in the current frame.
+		FrameCycles uint32
+
+		// The address of the pending interrupt, or zero if there is none.
+		PendingInterrupt uint16
+
+		// Whether the last instruction executed was EI, delaying the pending
+		// interrupt.
+		AfterEI bool
+
+		// The DIP switch bits of input port 2.
+		DIP byte
+
+		// The size of memory that follows the snapshot.
+		MemSize uint32
+	}
+)
+
+// WithStateDir sets the directory that save state slots are stored in.
+func WithStateDir(dir string) Option {
+	return func(m *Machine) {
+		m.sdir = dir
+	}
+}
+
+// SaveState writes the complete machine state to w.
+func (m *Machine) SaveState(w io.Writer) error {
+	mem := m.mem.ReadAll()
+
+	hdr := stateHeader{
+		Magic:   stateMagic,
+		Version: stateVersion,
+	}
+	if err := binary.Write(w, binary.LittleEndian, hdr); err != nil {
+		return err
+	}
+
+	s := machineState{
+		CPU:              m.c.State(),
+		ShiftOffset:      m.so,
+		ShiftData:        m.sd,
+		Watchdog:         m.wd,
+		Sound1:           m.snd1,
+		Sound2:           m.snd2,
+		FrameCycles:      m.fc,
+		PendingInterrupt: m.pi,
+		AfterEI:          m.ei,
+		DIP:              m.dip(),
+		MemSize:          uint32(len(mem)),
+	}
+	if err := binary.Write(w, binary.LittleEndian, s); err != nil {
+		return err
+	}
+
+	_, err := w.Write(mem)
+	return err
+}
+
+// LoadState restores the complete machine state from r.
+//
+// The state is only applied once it has been read in full, so the machine is
+// left untouched if an error is returned. The restored sound latches are sent
+// to the audio sink, so that the sounds playing match the restored state.
+func (m *Machine) LoadState(r io.Reader) error {
+	if err := m.restore(r); err != nil {
+		return err
+	}
+	m.resound()
+
+	return nil
+}
+
+// restore restores the complete machine state from r, as LoadState does, but
+// without sending the sound latches to the audio sink.
+func (m *Machine) restore(r io.Reader) error {
+	var hdr stateHeader
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+	if hdr.Magic != stateMagic {
+		return errors.New("not a save state")
+	}
+	if hdr.Version != stateVersion {
+		return fmt.Errorf("unsupported save state version %d", hdr.Version)
+	}
+
+	var s machineState
+	if err := binary.Read(r, binary.LittleEndian, &s); err != nil {
+		return fmt.Errorf("read state: %w", err)
+	}
+
+	mem := m.mem.ReadAll()
+	if int(s.MemSize) != len(mem) {
+		return fmt.Errorf(
+			"memory size mismatch: state has %d bytes, machine has %d",
+			s.MemSize, len(mem),
+		)
+	}
+
+	buf := make([]byte, s.MemSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return fmt.Errorf("read memory: %w", err)
+	}
+
+	m.c.SetState(s.CPU)
+	m.so = s.ShiftOffset
+	m.sd = s.ShiftData
+	m.wd = s.Watchdog
+	m.snd1 = s.Sound1
+	m.snd2 = s.Sound2
+	m.fc = s.FrameCycles
+	m.pi = s.PendingInterrupt
+	m.ei = s.AfterEI
+	m.dips = dipFromBits(s.DIP)
+	copy(mem, buf)
+
+	return nil
+}
+
+// SaveSlot writes the complete machine state to the given numbered slot.
+func (m *Machine) SaveSlot(slot int) error {
+	if err := os.MkdirAll(m.sdir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(m.slotPath(slot))
+	if err != nil {
+		return err
+	}
+
+	if err = m.SaveState(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// LoadSlot restores the complete machine state from the given numbered slot.
+func (m *Machine) LoadSlot(slot int) error {
+	f, err := os.Open(m.slotPath(slot))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return m.LoadState(f)
+}
+
+// slotPath returns the path of the save state file for the given slot.
+func (m *Machine) slotPath(slot int) string {
+	return filepath.Join(m.sdir, fmt.Sprintf("slot%d.state", slot))
+}
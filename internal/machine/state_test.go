@@ -0,0 +1,117 @@
+package machine
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// testAudio is an audio sink that records the values written to the sound
+// ports.
+type testAudio struct {
+	out [][2]byte
+}
+
+// Out implements Audio.
+func (a *testAudio) Out(port, data byte, _ int) {
+	a.out = append(a.out, [2]byte{port, data})
+}
+
+// Frame implements Audio.
+func (a *testAudio) Frame() error {
+	return nil
+}
+
+func TestSaveLoadState(t *testing.T) {
+	src := newTestMachine(t)
+	for i := 0; i < 10; i++ {
+		if err := src.frame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs := cpuState{
+		A: 0x12, B: 0x34, C: 0x56, D: 0x78, E: 0x9a, H: 0xbc, L: 0xde,
+		Flags:  0x02 | flagCY | flagAC | flagS,
+		SP:     0x23f0,
+		PC:     0x0024,
+		IE:     true,
+		Halted: true,
+		Cycles: 123456,
+	}
+	src.c.SetState(cs)
+	src.mem.Write(0x2100, 0xa5)
+	src.so, src.sd, src.pi = 3, 0xbeef, 0x0010
+
+	var b bytes.Buffer
+	if err := src.SaveState(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := newTestMachine(t)
+	if err := dst.LoadState(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := dst.c.State(); got != cs {
+		t.Errorf("CPU state = %+v, want %+v", got, cs)
+	}
+	if dst.so != src.so || dst.sd != src.sd || dst.pi != src.pi || dst.fc != src.fc {
+		t.Error("machine state differs after loading")
+	}
+	if want, got := dumpRAM(t, src), dumpRAM(t, dst); !bytes.Equal(got, want) {
+		t.Error("RAM differs after loading")
+	}
+}
+
+func TestLoadStateInvalid(t *testing.T) {
+	m := newTestMachine(t)
+	var b bytes.Buffer
+	if err := m.SaveState(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	// A truncated state must be rejected without touching the machine.
+	m.c.SetState(cpuState{Flags: 0x02, PC: 0x1234})
+	if err := m.LoadState(bytes.NewReader(b.Bytes()[:b.Len()-1])); err == nil {
+		t.Error("expected an error loading a truncated state")
+	}
+	if got := m.c.PC(); got != 0x1234 {
+		t.Errorf("PC = %04x after a failed load, want 1234", got)
+	}
+}
+
+func TestLoadStateSound(t *testing.T) {
+	a := &testAudio{}
+	m := newTestMachine(t, WithAudio(a))
+
+	// Save with the UFO sound on, then load with it off, and the other way
+	// round: the sink must hear the restored latches from silence.
+	m.snd1, m.snd2 = 0x01, 0x10
+	var ufo bytes.Buffer
+	if err := m.SaveState(&ufo); err != nil {
+		t.Fatal(err)
+	}
+	m.snd1, m.snd2 = 0x00, 0x00
+	var quiet bytes.Buffer
+	if err := m.SaveState(&quiet); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		state *bytes.Buffer
+		want  [][2]byte
+	}{
+		{&ufo, [][2]byte{{0x03, 0}, {0x05, 0}, {0x03, 0x01}, {0x05, 0x10}}},
+		{&quiet, [][2]byte{{0x03, 0}, {0x05, 0}, {0x03, 0}, {0x05, 0}}},
+	}
+	for _, tt := range tests {
+		a.out = nil
+		if err := m.LoadState(tt.state); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(a.out, tt.want) {
+			t.Errorf("sound ports = %v, want %v", a.out, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"log"
+)
+
+const (
+	// The Space Invaders board decodes 14 address lines, so the 16K address
+	// space is mirrored every $4000.
+	mappedSize = 0x4000
+	mappedMask = mappedSize - 1
+
+	// The ROM occupies the first 8K of the address space.
+	romSize = 0x2000
+)
+
+type (
+	// Mapped is an implementation of the Space Invaders memory with the
+	// address decoding of the original board.
+	//
+	// The Space Invaders memory is mapped as follows:
+	//
+	// $0000-$1FFF -> 8K ROM (read-only)
+	// $2000-$23FF -> 1K RAM
+	// $2400-$3FFF -> 7K Video RAM
+	// $4000+      -> Mirror of $0000-$3FFF
+	//
+	// Writes to the ROM are ignored, as they are by the board, and counted.
+	Mapped struct {
+		mem [mappedSize]byte
+
+		// The number of attempted writes to the ROM.
+		iw uint64
+
+		// Flag for logging writes to the ROM.
+		logWrites bool
+	}
+
+	// MappedOption is a functional option that modifies a field on the
+	// memory.
+	MappedOption func(*Mapped)
+)
+
+// WithROMWriteLogging enables logging of attempted writes to the ROM.
+func WithROMWriteLogging() MappedOption {
+	return func(m *Mapped) {
+		m.logWrites = true
+	}
+}
+
+// NewMapped returns an instantiated Space Invaders memory.
+func NewMapped(opts ...MappedOption) *Mapped {
+	m := &Mapped{}
+
+	for _, o := range opts {
+		o(m)
+	}
+
+	return m
+}
+
+// Read returns the value from memory at the given address.
+func (m *Mapped) Read(addr uint16) byte {
+	return m.mem[addr&mappedMask]
+}
+
+// ReadAll returns the full memory contents, without mirrors.
+func (m *Mapped) ReadAll() []byte {
+	return m.mem[:]
+}
+
+// Write writes the value v into memory at the given address.
+//
+// Writes to the ROM are ignored.
+func (m *Mapped) Write(addr uint16, v byte) {
+	a := addr & mappedMask
+	if a < romSize {
+		m.iw++
+		if m.logWrites {
+			log.Printf("ignored write of %02x to ROM address %04x", v, addr)
+		}
+		return
+	}
+
+	m.mem[a] = v
+}
+
+// ROMWrites returns the number of attempted writes to the ROM.
+func (m *Mapped) ROMWrites() uint64 {
+	return m.iw
+}
+
+// LoadROM loads the Space Invaders ROM into memory.
+//
+// See Basic.LoadROM for details on the ROM structure, the supported formats
+// and verification.
+func (m *Mapped) LoadROM(path string) (*ROMReport, error) {
+	return loadROM(m.mem[:], path)
+}
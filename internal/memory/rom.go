@@ -1,21 +1,15 @@
 package memory
 
 import (
+	"archive/zip"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
-// romOffsets represents the memory offsets that each ROM part begins it's data
-// range at.
-var romOffsets = map[string]uint32{
-	"invaders.h": 0,
-	"invaders.g": 0x800,
-	"invaders.f": 0x1000,
-	"invaders.e": 0x1800,
-}
-
 // LoadROM loads the Space Invaders ROM into memory.
 //
 // The ROM itself is broken down into 4 parts with the following address ranges:
@@ -24,35 +18,189 @@ var romOffsets = map[string]uint32{
 // $0800-$0fff: invaders.g
 // $1000-$17ff: invaders.f
 // $1800-$1fff: invaders.e
-func (b Basic) LoadROM(dir string) error {
-	if dir == "" {
-		return errors.New("ROM directory cannot be empty")
+//
+// The path may be a directory containing the parts, a MAME style zip archive
+// containing the parts, or a single 8K image of the parts concatenated in
+// order. Parts in a zip archive are matched by name, or by CRC32 if no entry
+// has the expected name.
+//
+// The parts are verified against the known ROM sets and a report is returned
+// describing the set identified and any problems found. An error is returned
+// if any part is missing or the wrong size. Parts that are in the wrong order
+// are loaded at the address of the part they contain.
+func (b Basic) LoadROM(path string) (*ROMReport, error) {
+	return loadROM(b, path)
+}
+
+// loadROM loads the Space Invaders ROM from the given path into mem.
+func loadROM(mem []byte, path string) (*ROMReport, error) {
+	if path == "" {
+		return nil, errors.New("ROM path cannot be empty")
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files map[string][]byte
+	switch {
+	case fi.IsDir():
+		files, err = readROMDir(path)
+	case strings.EqualFold(filepath.Ext(path), ".zip"):
+		files, err = readROMZip(path)
+	default:
+		files, err = readROMImage(path)
 	}
-	if _, err := os.Stat(dir); err != nil {
-		return err
+	if err != nil {
+		return nil, err
 	}
 
-	for rom, offset := range romOffsets {
-		if err := b.loadROMPart(dir, rom, offset); err != nil {
-			return err
+	r := verify(files)
+	if !r.OK() {
+		return r, fmt.Errorf("could not load ROM: %s", r)
+	}
+
+	for _, set := range knownSets {
+		if set.Name != r.Set {
+			continue
+		}
+		for _, p := range set.Parts {
+			copy(mem[p.Offset:], partData(set, p, files))
 		}
 	}
 
-	return nil
+	return r, nil
 }
 
-func (b Basic) loadROMPart(dir, part string, offset uint32) error {
-	path := filepath.Join(dir, part)
+// partData returns the contents of the given part of set from the given ROM
+// files, keyed by name.
+//
+// The part is found by its contents, so that files in the wrong order are
+// still loaded correctly. If no file matches the part, such as a bad dump, the
+// file with the name of the part is returned.
+func partData(set romSet, p romPart, files map[string][]byte) []byte {
+	if data := files[p.Name]; p.matches(data) {
+		return data
+	}
+	for _, o := range set.Parts {
+		if data := files[o.Name]; p.matches(data) {
+			return data
+		}
+	}
+
+	return files[p.Name]
+}
+
+// readROMDir returns the contents of every part of every known set that is
+// present in dir, keyed by part name.
+func readROMDir(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for _, set := range knownSets {
+		for _, p := range set.Parts {
+			if _, ok := files[p.Name]; ok {
+				continue
+			}
+
+			path := filepath.Join(dir, p.Name)
+			data, err := ioutil.ReadFile(path)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("could not read ROM part (%q): %w", path, err)
+			}
+			files[p.Name] = data
+		}
+	}
+
+	return files, nil
+}
 
-	rf, err := os.Open(path)
+// readROMZip returns the contents of every part of every known set that is
+// present in the zip archive at path, keyed by part name.
+//
+// Entries are matched to parts by name, ignoring any directory and case. Parts
+// with no entry of the same name are matched to an entry with the same CRC32.
+func readROMZip(path string) (map[string][]byte, error) {
+	zr, err := zip.OpenReader(path)
 	if err != nil {
-		return fmt.Errorf("could not open ROM part (%q): %w", path, err)
+		return nil, fmt.Errorf("could not open ROM archive (%q): %w", path, err)
 	}
-	defer rf.Close()
+	defer zr.Close()
+
+	files := make(map[string][]byte)
+	for _, set := range knownSets {
+		for _, p := range set.Parts {
+			if _, ok := files[p.Name]; ok {
+				continue
+			}
 
-	if _, err = rf.Read(b[offset:]); err != nil {
-		return fmt.Errorf("could not read ROM part (%q): %w", path, err)
+			zf := findZipEntry(zr.File, p)
+			if zf == nil {
+				continue
+			}
+
+			data, err := readZipEntry(zf)
+			if err != nil {
+				return nil, fmt.Errorf("could not read ROM part (%q in %q): %w", zf.Name, path, err)
+			}
+			files[p.Name] = data
+		}
+	}
+
+	return files, nil
+}
+
+// findZipEntry returns the zip entry for the given part, or nil if there is
+// none.
+func findZipEntry(zfs []*zip.File, p romPart) *zip.File {
+	for _, zf := range zfs {
+		if strings.EqualFold(filepath.Base(zf.Name), p.Name) {
+			return zf
+		}
+	}
+	for _, zf := range zfs {
+		if zf.CRC32 == p.CRC {
+			return zf
+		}
 	}
 
 	return nil
 }
+
+// readZipEntry returns the contents of the given zip entry.
+func readZipEntry(zf *zip.File) ([]byte, error) {
+	rc, err := zf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return ioutil.ReadAll(rc)
+}
+
+// readROMImage returns every part of every known set from the single image at
+// path, keyed by part name.
+//
+// The image must contain the parts concatenated in order, as they appear in
+// memory.
+func readROMImage(path string) (map[string][]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read ROM image (%q): %w", path, err)
+	}
+	if len(data) != romSize {
+		return nil, fmt.Errorf(
+			"ROM image (%q) is %d bytes, expected %d", path, len(data), romSize,
+		)
+	}
+
+	files := make(map[string][]byte)
+	for _, set := range knownSets {
+		for _, p := range set.Parts {
+			files[p.Name] = data[p.Offset : int(p.Offset)+p.Size]
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,350 @@
+package memory
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testParts returns the contents of four synthetic ROM parts, each filled with
+// a different pattern.
+func testParts() [][]byte {
+	parts := make([][]byte, 4)
+	for i := range parts {
+		parts[i] = make([]byte, 0x800)
+		for j := range parts[i] {
+			parts[i][j] = byte(j*(i+3) + i)
+		}
+	}
+
+	return parts
+}
+
+// testSet returns a ROM set of the given parts, with the given part names.
+func testSet(name string, names []string, parts [][]byte) romSet {
+	set := romSet{Name: name, Description: "Test Set " + name}
+	for i, data := range parts {
+		sum := sha1.Sum(data)
+		set.Parts = append(set.Parts, romPart{
+			Name:   names[i],
+			Offset: uint32(i * 0x800),
+			Size:   len(data),
+			CRC:    crc32.ChecksumIEEE(data),
+			SHA1:   hex.EncodeToString(sum[:]),
+		})
+	}
+
+	return set
+}
+
+// withSets replaces the known ROM sets with the given sets until the test
+// ends.
+func withSets(t *testing.T, sets ...romSet) {
+	t.Helper()
+
+	old := knownSets
+	knownSets = sets
+	t.Cleanup(func() { knownSets = old })
+}
+
+// invadersNames are the part names of the invaders set.
+var invadersNames = []string{"invaders.h", "invaders.g", "invaders.f", "invaders.e"}
+
+// withTestSet replaces the known ROM sets with a single set of the given
+// parts, named like the parts of the invaders set, until the test ends.
+func withTestSet(t *testing.T, parts [][]byte) {
+	t.Helper()
+
+	withSets(t, testSet("test", invadersNames, parts))
+}
+
+func TestLoadROM(t *testing.T) {
+	parts := testParts()
+	withTestSet(t, parts)
+
+	corrupt := append([]byte(nil), parts[2]...)
+	corrupt[0x100] ^= 0xff
+
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  ROMReport
+		mem   [][]byte
+	}{
+		{
+			name: "good",
+			files: map[string][]byte{
+				"invaders.h": parts[0],
+				"invaders.g": parts[1],
+				"invaders.f": parts[2],
+				"invaders.e": parts[3],
+			},
+			want: ROMReport{Exact: true, Misplaced: map[string]string{}},
+			mem:  parts,
+		},
+		{
+			name: "misplaced",
+			files: map[string][]byte{
+				"invaders.h": parts[1],
+				"invaders.g": parts[0],
+				"invaders.f": parts[2],
+				"invaders.e": parts[3],
+			},
+			want: ROMReport{
+				Misplaced: map[string]string{
+					"invaders.h": "invaders.g",
+					"invaders.g": "invaders.h",
+				},
+			},
+			mem: parts,
+		},
+		{
+			name: "corrupt",
+			files: map[string][]byte{
+				"invaders.h": parts[0],
+				"invaders.g": parts[1],
+				"invaders.f": corrupt,
+				"invaders.e": parts[3],
+			},
+			want: ROMReport{
+				BadDump:   []string{"invaders.f"},
+				Misplaced: map[string]string{},
+			},
+			mem: [][]byte{parts[0], parts[1], corrupt, parts[3]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rom")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			for name, data := range tt.files {
+				if err = ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			mem := make(Basic, 0x4000)
+			r, err := mem.LoadROM(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.want.Set, tt.want.Description = "test", "Test Set test"
+			if !reflect.DeepEqual(*r, tt.want) {
+				t.Errorf("report = %+v, want %+v", *r, tt.want)
+			}
+			for i, data := range tt.mem {
+				if !bytes.Equal(mem[i*0x800:(i+1)*0x800], data) {
+					t.Errorf("part at $%04x not loaded as expected", i*0x800)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadROMMissing(t *testing.T) {
+	parts := testParts()
+	withTestSet(t, parts)
+
+	dir, err := ioutil.TempDir("", "rom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string][]byte{
+		"invaders.h": parts[0],
+		"invaders.g": parts[1][:0x400],
+		"invaders.f": parts[2],
+	}
+	for name, data := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := make(Basic, 0x4000).LoadROM(dir)
+	if err == nil {
+		t.Fatal("expected an error loading an incomplete set")
+	}
+	if want := []string{"invaders.e"}; !reflect.DeepEqual(r.Missing, want) {
+		t.Errorf("missing = %v, want %v", r.Missing, want)
+	}
+	if want := []string{"invaders.g"}; !reflect.DeepEqual(r.WrongSize, want) {
+		t.Errorf("wrong size = %v, want %v", r.WrongSize, want)
+	}
+}
+
+// writeROMZip writes a zip archive of the given entries, in order, to a new
+// file and returns its path.
+func writeROMZip(t *testing.T, dir string, entries [][2]string, data map[string][]byte) string {
+	t.Helper()
+
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for _, e := range entries {
+		w, err := zw.Create(e[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write(data[e[1]]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "invaders.zip")
+	if err := ioutil.WriteFile(path, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadROMZip(t *testing.T) {
+	parts := testParts()
+	withTestSet(t, parts)
+
+	// An unknown part is in no set, so is not found by CRC either.
+	data := map[string][]byte{
+		"h":       parts[0],
+		"g":       parts[1],
+		"f":       parts[2],
+		"e":       parts[3],
+		"unknown": make([]byte, 0x800),
+	}
+
+	tests := []struct {
+		name    string
+		entries [][2]string
+		missing []string
+	}{
+		{
+			name:    "by name",
+			entries: [][2]string{{"invaders.h", "h"}, {"invaders.g", "g"}, {"invaders.f", "f"}, {"invaders.e", "e"}},
+		},
+		{
+			name:    "by name in a directory, ignoring case",
+			entries: [][2]string{{"invaders/INVADERS.E", "e"}, {"invaders/Invaders.F", "f"}, {"invaders/invaders.G", "g"}, {"invaders/invaders.h", "h"}},
+		},
+		{
+			name:    "by CRC",
+			entries: [][2]string{{"1.bin", "h"}, {"2.bin", "g"}, {"3.bin", "f"}, {"4.bin", "e"}},
+		},
+		{
+			name:    "by name and CRC",
+			entries: [][2]string{{"invaders.h", "h"}, {"sv02.bin", "g"}, {"invaders.f", "f"}, {"ic33.rom", "e"}},
+		},
+		{
+			name:    "missing part",
+			entries: [][2]string{{"invaders.h", "h"}, {"invaders.g", "g"}, {"unknown.bin", "unknown"}},
+			missing: []string{"invaders.f", "invaders.e"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rom")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			path := writeROMZip(t, dir, tt.entries, data)
+
+			mem := make(Basic, 0x4000)
+			r, err := mem.LoadROM(path)
+			if tt.missing != nil {
+				if err == nil {
+					t.Fatal("expected an error loading an incomplete set")
+				}
+				if !reflect.DeepEqual(r.Missing, tt.missing) {
+					t.Errorf("missing = %v, want %v", r.Missing, tt.missing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !r.Exact {
+				t.Errorf("set not identified exactly: %s", r)
+			}
+			for i, data := range parts {
+				if !bytes.Equal(mem[i*0x800:(i+1)*0x800], data) {
+					t.Errorf("part at $%04x not loaded as expected", i*0x800)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadROMImage(t *testing.T) {
+	parts := testParts()
+	withTestSet(t, parts)
+
+	tests := []struct {
+		name      string
+		image     [][]byte
+		ok        bool
+		misplaced map[string]string
+	}{
+		{"in order", parts, true, map[string]string{}},
+		{
+			"out of order",
+			[][]byte{parts[0], parts[2], parts[1], parts[3]},
+			true,
+			map[string]string{"invaders.g": "invaders.f", "invaders.f": "invaders.g"},
+		},
+		{"too short", parts[:3], false, nil},
+		{"too long", append(parts[:4:4], parts[0]), false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rom")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "invaders.rom")
+			if err = ioutil.WriteFile(path, bytes.Join(tt.image, nil), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			mem := make(Basic, 0x4000)
+			r, err := mem.LoadROM(path)
+			if !tt.ok {
+				if err == nil {
+					t.Fatal("expected an error loading an image of the wrong size")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(r.Misplaced, tt.misplaced) {
+				t.Errorf("misplaced = %v, want %v", r.Misplaced, tt.misplaced)
+			}
+			for i, data := range parts {
+				if !bytes.Equal(mem[i*0x800:(i+1)*0x800], data) {
+					t.Errorf("part at $%04x not loaded as expected", i*0x800)
+				}
+			}
+		})
+	}
+}
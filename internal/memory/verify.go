@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strings"
+)
+
+type (
+	// romPart describes a single part of a known ROM set.
+	romPart struct {
+		Name   string
+		Offset uint32
+		Size   int
+		CRC    uint32
+		SHA1   string
+	}
+
+	// romSet describes a known ROM set.
+	romSet struct {
+		Name        string
+		Description string
+		Parts       []romPart
+	}
+
+	// ROMReport describes how a set of ROM files compares to the known ROM
+	// sets.
+	ROMReport struct {
+		// The name and description of the identified set. If the files do
+		// not match any set exactly, this is the closest set.
+		Set         string
+		Description string
+
+		// True if every part matched the set exactly.
+		Exact bool
+
+		// Parts that could not be found.
+		Missing []string
+
+		// Parts that are not the expected size.
+		WrongSize []string
+
+		// Parts that are the expected size but do not match the expected
+		// checksums.
+		BadDump []string
+
+		// Parts that contain the data of a different part of the set,
+		// indicating the files are in the wrong order. Maps the file name to
+		// the part it contains.
+		Misplaced map[string]string
+	}
+)
+
+// knownSets are the known Space Invaders ROM sets, as catalogued by MAME.
+//
+// The first set is the one that is loaded when no set can be identified. A
+// set is only listed once its part names, sizes and checksums have been
+// checked against the MAME database, as a wrong checksum would report every
+// good dump of the set as bad. Clones are identified by the same rules as the
+// parent, and may share parts with it.
+var knownSets = []romSet{
+	{
+		Name:        "invaders",
+		Description: "Space Invaders / Space Invaders M (Midway)",
+		Parts: []romPart{
+			{"invaders.h", 0x0000, 0x800, 0x734f5ad8, "ff6200af4c9110d8181249cbcef1a8a40fa40b7f"},
+			{"invaders.g", 0x0800, 0x800, 0x6bfaca4a, "16f48649b531bdef8c2d1446c429b5f414524350"},
+			{"invaders.f", 0x1000, 0x800, 0x0ccead96, "537aef03468f63c5b9e11dd61e253f7ae17d9743"},
+			{"invaders.e", 0x1800, 0x800, 0x14e538b0, "1d6ca0c99f9df71e2990b610deb9d7da0125e2d8"},
+		},
+	},
+}
+
+// OK returns true if the files can be loaded, that is no part is missing or
+// the wrong size.
+func (r *ROMReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.WrongSize) == 0
+}
+
+// String returns a human readable summary of the report.
+func (r *ROMReport) String() string {
+	if r.Exact {
+		return fmt.Sprintf("identified ROM set %q (%s)", r.Set, r.Description)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "unrecognised ROM set, closest match %q (%s)", r.Set, r.Description)
+	if len(r.Missing) > 0 {
+		fmt.Fprintf(&b, "; missing: %s", strings.Join(r.Missing, ", "))
+	}
+	if len(r.WrongSize) > 0 {
+		fmt.Fprintf(&b, "; wrong size: %s", strings.Join(r.WrongSize, ", "))
+	}
+	if len(r.BadDump) > 0 {
+		fmt.Fprintf(&b, "; bad dump: %s", strings.Join(r.BadDump, ", "))
+	}
+	if len(r.Misplaced) > 0 {
+		names := make([]string, 0, len(r.Misplaced))
+		for n := range r.Misplaced {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		wrong := make([]string, 0, len(names))
+		for _, n := range names {
+			wrong = append(wrong, fmt.Sprintf("%s contains %s", n, r.Misplaced[n]))
+		}
+		fmt.Fprintf(&b, "; wrong order: %s", strings.Join(wrong, ", "))
+	}
+
+	return b.String()
+}
+
+// verify compares the given ROM files, keyed by name, to the known ROM sets
+// and reports on the closest match.
+func verify(files map[string][]byte) *ROMReport {
+	var best *ROMReport
+	bestScore := -1
+
+	for _, set := range knownSets {
+		r, score := verifySet(set, files)
+		if score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+
+	return best
+}
+
+// verifySet compares the given ROM files to the given ROM set. The number of
+// parts that match exactly is returned along with the report.
+func verifySet(set romSet, files map[string][]byte) (*ROMReport, int) {
+	r := &ROMReport{
+		Set:         set.Name,
+		Description: set.Description,
+		Misplaced:   make(map[string]string),
+	}
+
+	var matched int
+	for _, p := range set.Parts {
+		data, ok := files[p.Name]
+		switch {
+		case !ok:
+			r.Missing = append(r.Missing, p.Name)
+		case len(data) != p.Size:
+			r.WrongSize = append(r.WrongSize, p.Name)
+		case p.matches(data):
+			matched++
+		default:
+			// Check whether the data belongs to another part of the set.
+			for _, o := range set.Parts {
+				if o.Name != p.Name && o.matches(data) {
+					r.Misplaced[p.Name] = o.Name
+				}
+			}
+			if _, ok := r.Misplaced[p.Name]; !ok {
+				r.BadDump = append(r.BadDump, p.Name)
+			}
+		}
+	}
+	r.Exact = matched == len(set.Parts)
+
+	return r, matched
+}
+
+// matches returns true if data matches the size and checksums of the part.
+func (p romPart) matches(data []byte) bool {
+	if len(data) != p.Size || crc32.ChecksumIEEE(data) != p.CRC {
+		return false
+	}
+
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:]) == p.SHA1
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestVerifyClones(t *testing.T) {
+	parts := testParts()
+
+	// The clone shares its first two parts with the parent, under different
+	// names, and has parts of its own in place of the others.
+	cloneParts := [][]byte{parts[0], parts[1], append([]byte(nil), parts[2]...), append([]byte(nil), parts[3]...)}
+	cloneParts[2][0], cloneParts[3][0] = 0xaa, 0xbb
+	cloneNames := []string{"clone.1", "clone.2", "clone.3", "clone.4"}
+
+	withSets(t,
+		testSet("parent", invadersNames, parts),
+		testSet("clone", cloneNames, cloneParts),
+	)
+
+	// files returns the given parts under the given names.
+	files := func(names []string, parts ...[]byte) map[string][]byte {
+		fs := make(map[string][]byte)
+		for i, n := range names {
+			fs[n] = parts[i]
+		}
+		return fs
+	}
+
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  ROMReport
+		mem   [][]byte
+	}{
+		{
+			name:  "parent",
+			files: files(invadersNames, parts...),
+			want:  ROMReport{Set: "parent", Description: "Test Set parent", Exact: true, Misplaced: map[string]string{}},
+			mem:   parts,
+		},
+		{
+			name:  "clone",
+			files: files(cloneNames, cloneParts...),
+			want:  ROMReport{Set: "clone", Description: "Test Set clone", Exact: true, Misplaced: map[string]string{}},
+			mem:   cloneParts,
+		},
+		{
+			name:  "reordered clone",
+			files: files(cloneNames, cloneParts[0], cloneParts[1], cloneParts[3], cloneParts[2]),
+			want: ROMReport{
+				Set:         "clone",
+				Description: "Test Set clone",
+				Misplaced:   map[string]string{"clone.3": "clone.4", "clone.4": "clone.3"},
+			},
+			mem: cloneParts,
+		},
+		{
+			name:  "reordered parent",
+			files: files(invadersNames, parts[3], parts[1], parts[2], parts[0]),
+			want: ROMReport{
+				Set:         "parent",
+				Description: "Test Set parent",
+				Misplaced:   map[string]string{"invaders.h": "invaders.e", "invaders.e": "invaders.h"},
+			},
+			mem: parts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rom")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			for name, data := range tt.files {
+				if err = ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			mem := make(Basic, 0x4000)
+			r, err := mem.LoadROM(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*r, tt.want) {
+				t.Errorf("report = %+v, want %+v", *r, tt.want)
+			}
+			for i, data := range tt.mem {
+				if !bytes.Equal(mem[i*0x800:(i+1)*0x800], data) {
+					t.Errorf("part at $%04x not loaded as expected", i*0x800)
+				}
+			}
+		})
+	}
+}
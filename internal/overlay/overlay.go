@@ -0,0 +1,220 @@
+// Package overlay emulates the coloured cellophane overlays that were stuck
+// over the monochrome monitor of the Space Invaders cabinets.
+package overlay
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Screen dimensions, in the rotated co-ordinates of machine.Screen: x runs
+// from left to right and y from the bottom of the screen to the top.
+const screenW, screenH = 224, 256
+
+type (
+	// Overlay is a colour overlay. Every lit pixel takes the colour of the
+	// last region that contains it, or the foreground colour if no region
+	// does.
+	Overlay struct {
+		// The colour of lit pixels outside every region.
+		Foreground Color `json:"foreground"`
+
+		// The coloured regions of the overlay.
+		Regions []Region `json:"regions"`
+
+		// The colour of every pixel, indexed by y*screenW+x.
+		colors []color.RGBA
+	}
+
+	// Region is a coloured rectangle of an overlay, in the rotated screen
+	// co-ordinates.
+	Region struct {
+		// The bottom left corner of the region.
+		X int `json:"x"`
+		Y int `json:"y"`
+
+		// The size of the region.
+		W int `json:"w"`
+		H int `json:"h"`
+
+		// The colour of lit pixels within the region.
+		Color Color `json:"color"`
+	}
+
+	// Color is an RGB colour, written as a "#rrggbb" string in overlay files.
+	Color color.RGBA
+)
+
+var (
+	white = Color{0xff, 0xff, 0xff, 0xff}
+	red   = Color{0xff, 0x20, 0x20, 0xff}
+	green = Color{0x20, 0xff, 0x20, 0xff}
+)
+
+// presets are the built in overlays, keyed by name.
+var presets = map[string]Overlay{
+	// The plain white of the monitor, without an overlay.
+	"mono": {
+		Foreground: white,
+	},
+
+	// The upright cabinet: a red strip over the flying saucer at the top of
+	// the screen and a green strip over the shields and the player's base.
+	// Below the base only the remaining lives are green, leaving the credit
+	// count white.
+	"upright": {
+		Foreground: white,
+		Regions: []Region{
+			{X: 0, Y: 192, W: 224, H: 32, Color: red},
+			{X: 0, Y: 16, W: 224, H: 56, Color: green},
+			{X: 16, Y: 0, W: 118, H: 16, Color: green},
+		},
+	},
+
+	// The cocktail table, where the players sit at opposite ends and the
+	// screen is flipped for player 2: a green strip over the shields and base
+	// at both ends of the screen.
+	"cocktail": {
+		Foreground: white,
+		Regions: []Region{
+			{X: 0, Y: 16, W: 224, H: 56, Color: green},
+			{X: 0, Y: 184, W: 224, H: 56, Color: green},
+		},
+	},
+
+	// The TV version, which coloured the screen in horizontal bands from the
+	// flying saucer at the top down to the player's base.
+	"tv": {
+		Foreground: white,
+		Regions: []Region{
+			{X: 0, Y: 192, W: 224, H: 32, Color: red},
+			{X: 0, Y: 160, W: 224, H: 32, Color: Color{0xff, 0x40, 0xff, 0xff}},
+			{X: 0, Y: 128, W: 224, H: 32, Color: Color{0x40, 0xff, 0xff, 0xff}},
+			{X: 0, Y: 96, W: 224, H: 32, Color: Color{0xff, 0xff, 0x40, 0xff}},
+			{X: 0, Y: 72, W: 224, H: 24, Color: Color{0x40, 0x80, 0xff, 0xff}},
+			{X: 0, Y: 16, W: 224, H: 56, Color: green},
+		},
+	},
+}
+
+// Presets returns the names of the built in overlays.
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for n := range presets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Load returns the built in overlay with the given name or, if there is none,
+// the overlay read from the JSON file at the given path.
+func Load(name string) (*Overlay, error) {
+	if o, ok := presets[strings.ToLower(name)]; ok {
+		return New(o.Foreground, o.Regions...)
+	}
+
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"overlay %q is not one of %s and could not be read: %w",
+			name, strings.Join(Presets(), ", "), err,
+		)
+	}
+
+	var o Overlay
+	if err = json.Unmarshal(b, &o); err != nil {
+		return nil, fmt.Errorf("parse overlay (%q): %w", name, err)
+	}
+	if o.Foreground == (Color{}) {
+		o.Foreground = white
+	}
+
+	return New(o.Foreground, o.Regions...)
+}
+
+// New returns an overlay of the given regions over the given foreground
+// colour.
+//
+// An error is returned if any region lies outside the 224x256 screen.
+func New(fg Color, regions ...Region) (*Overlay, error) {
+	o := &Overlay{
+		Foreground: fg,
+		Regions:    regions,
+		colors:     make([]color.RGBA, screenW*screenH),
+	}
+
+	for i := range o.colors {
+		o.colors[i] = color.RGBA(fg)
+	}
+	for i, r := range regions {
+		if r.W <= 0 || r.H <= 0 || r.X < 0 || r.Y < 0 || r.X+r.W > screenW || r.Y+r.H > screenH {
+			return nil, fmt.Errorf(
+				"region %d (%dx%d at %d,%d) does not fit on the %dx%d screen",
+				i, r.W, r.H, r.X, r.Y, screenW, screenH,
+			)
+		}
+
+		for y := r.Y; y < r.Y+r.H; y++ {
+			for x := r.X; x < r.X+r.W; x++ {
+				o.colors[y*screenW+x] = color.RGBA(r.Color)
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// At returns the colour of a lit pixel at the given co-ordinates.
+func (o *Overlay) At(x, y int) color.RGBA {
+	if x < 0 || y < 0 || x >= screenW || y >= screenH {
+		return color.RGBA(o.Foreground)
+	}
+
+	return o.colors[y*screenW+x]
+}
+
+// Colors returns the distinct colours of lit pixels, foreground first.
+func (o *Overlay) Colors() []color.RGBA {
+	cs := []color.RGBA{color.RGBA(o.Foreground)}
+	for _, r := range o.Regions {
+		c, dup := color.RGBA(r.Color), false
+		for _, e := range cs {
+			dup = dup || e == c
+		}
+		if !dup {
+			cs = append(cs, c)
+		}
+	}
+
+	return cs
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c Color) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Color) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	// Sscanf would accept a short or signed component, so the hex digits are
+	// decoded directly.
+	rgb, err := hex.DecodeString(strings.TrimPrefix(s, "#"))
+	if err != nil || len(rgb) != 3 || !strings.HasPrefix(s, "#") {
+		return fmt.Errorf("invalid colour %q: must be #rrggbb", s)
+	}
+	*c = Color{rgb[0], rgb[1], rgb[2], 0xff}
+
+	return nil
+}
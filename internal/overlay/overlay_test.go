@@ -0,0 +1,178 @@
+package overlay
+
+import (
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	want := []string{"cocktail", "mono", "tv", "upright"}
+	if got := Presets(); !reflect.DeepEqual(got, want) {
+		t.Errorf("presets = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"mono", 100, 200, color.RGBA(white)},
+		{"Mono", 0, 0, color.RGBA(white)},
+		{"upright", 100, 200, color.RGBA(red)},
+		{"upright", 100, 40, color.RGBA(green)},
+		{"upright", 20, 8, color.RGBA(green)},
+		{"upright", 200, 8, color.RGBA(white)},
+		{"UPRIGHT", 100, 100, color.RGBA(white)},
+		{"cocktail", 100, 40, color.RGBA(green)},
+		{"cocktail", 100, 200, color.RGBA(green)},
+		{"cocktail", 100, 100, color.RGBA(white)},
+		{"tv", 100, 200, color.RGBA(red)},
+		{"tv", 100, 80, color.RGBA{0x40, 0x80, 0xff, 0xff}},
+		{"tv", 100, 240, color.RGBA(white)},
+	}
+	for _, tt := range tests {
+		o, err := Load(tt.name)
+		if err != nil {
+			t.Errorf("Load(%q): %v", tt.name, err)
+			continue
+		}
+		if got := o.At(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: At(%d, %d) = %v, want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		json   string
+		points map[[2]int]color.RGBA
+		colors []color.RGBA
+	}{
+		{
+			name: "default foreground",
+			json: `{"regions": [{"x": 0, "y": 0, "w": 10, "h": 10, "color": "#ff0000"}]}`,
+			points: map[[2]int]color.RGBA{
+				{0, 0}:   {0xff, 0, 0, 0xff},
+				{9, 9}:   {0xff, 0, 0, 0xff},
+				{10, 9}:  color.RGBA(white),
+				{9, 10}:  color.RGBA(white),
+				{-1, 0}:  color.RGBA(white),
+				{0, 256}: color.RGBA(white),
+			},
+			colors: []color.RGBA{color.RGBA(white), {0xff, 0, 0, 0xff}},
+		},
+		{
+			name: "foreground",
+			json: `{"foreground": "#00FF80"}`,
+			points: map[[2]int]color.RGBA{
+				{0, 0}:     {0, 0xff, 0x80, 0xff},
+				{223, 255}: {0, 0xff, 0x80, 0xff},
+			},
+			colors: []color.RGBA{{0, 0xff, 0x80, 0xff}},
+		},
+		{
+			// The last region containing a pixel gives it its colour.
+			name: "overlapping",
+			json: `{"regions": [
+				{"x": 0, "y": 0, "w": 224, "h": 256, "color": "#0000ff"},
+				{"x": 0, "y": 100, "w": 224, "h": 50, "color": "#00ff00"},
+				{"x": 50, "y": 120, "w": 10, "h": 10, "color": "#0000ff"}
+			]}`,
+			points: map[[2]int]color.RGBA{
+				{0, 0}:     {0, 0, 0xff, 0xff},
+				{0, 100}:   {0, 0xff, 0, 0xff},
+				{55, 125}:  {0, 0, 0xff, 0xff},
+				{223, 149}: {0, 0xff, 0, 0xff},
+				{223, 150}: {0, 0, 0xff, 0xff},
+			},
+			colors: []color.RGBA{color.RGBA(white), {0, 0, 0xff, 0xff}, {0, 0xff, 0, 0xff}},
+		},
+		{
+			name: "full screen",
+			json: `{"regions": [{"x": 0, "y": 0, "w": 224, "h": 256, "color": "#123456"}]}`,
+			points: map[[2]int]color.RGBA{
+				{0, 0}:     {0x12, 0x34, 0x56, 0xff},
+				{223, 255}: {0x12, 0x34, 0x56, 0xff},
+			},
+			colors: []color.RGBA{color.RGBA(white), {0x12, 0x34, 0x56, 0xff}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := Load(writeOverlay(t, tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for p, want := range tt.points {
+				if got := o.At(p[0], p[1]); got != want {
+					t.Errorf("At(%d, %d) = %v, want %v", p[0], p[1], got, want)
+				}
+			}
+			if got := o.Colors(); !reflect.DeepEqual(got, tt.colors) {
+				t.Errorf("colors = %v, want %v", got, tt.colors)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"not json", `regions`},
+		{"negative x", `{"regions": [{"x": -1, "y": 0, "w": 10, "h": 10, "color": "#ff0000"}]}`},
+		{"negative y", `{"regions": [{"x": 0, "y": -1, "w": 10, "h": 10, "color": "#ff0000"}]}`},
+		{"too wide", `{"regions": [{"x": 1, "y": 0, "w": 224, "h": 10, "color": "#ff0000"}]}`},
+		{"too high", `{"regions": [{"x": 0, "y": 200, "w": 10, "h": 57, "color": "#ff0000"}]}`},
+		{"empty", `{"regions": [{"x": 0, "y": 0, "w": 0, "h": 10, "color": "#ff0000"}]}`},
+		{"negative size", `{"regions": [{"x": 10, "y": 10, "w": 10, "h": -5, "color": "#ff0000"}]}`},
+		{"colour name", `{"foreground": "red"}`},
+		{"short colour", `{"foreground": "#fff"}`},
+		{"long colour", `{"foreground": "#ff00001"}`},
+		{"colour without hash", `{"foreground": "ff0000"}`},
+		{"bad hex digit", `{"foreground": "#12345g"}`},
+		{"space in colour", `{"foreground": "# 12345"}`},
+		{"sign in colour", `{"foreground": "#+12345"}`},
+		{"number colour", `{"foreground": 16711680}`},
+		{"bad region colour", `{"regions": [{"x": 0, "y": 0, "w": 10, "h": 10, "color": "#ff00zz"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(writeOverlay(t, tt.json)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	if _, err := Load(filepath.Join(os.TempDir(), "no-such-overlay.json")); err == nil {
+		t.Error("expected an error loading a missing overlay")
+	}
+}
+
+// writeOverlay writes the JSON of an overlay to a file, removed when the test
+// ends, and returns its path.
+func writeOverlay(t *testing.T, json string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "overlay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "overlay.json")
+	if err = ioutil.WriteFile(path, []byte(json), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
@@ -0,0 +1,127 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+)
+
+// gifFrameSkip is the number of emulated frames per GIF frame. Most GIF
+// viewers do not honour delays shorter than 2/100 second, so recording every
+// frame of the ~59.54Hz machine would play back too slowly.
+const gifFrameSkip = 2
+
+// netscapeLoop is the application extension that makes the GIF loop forever.
+var netscapeLoop = []byte{
+	0x21, 0xff, 0x0b, 'N', 'E', 'T', 'S', 'C', 'A', 'P', 'E', '2', '.', '0',
+	0x03, 0x01, 0x00, 0x00, 0x00,
+}
+
+// gifWriter writes an animated GIF one frame at a time, rather than holding
+// every frame in memory as gif.EncodeAll does.
+//
+// Each frame is encoded as a single frame GIF with a global colour table. The
+// header of the first is written to the file, followed by the frame data of
+// every one.
+type gifWriter struct {
+	f   *os.File
+	w   *bufio.Writer
+	pal color.Palette
+
+	// The palette index of each colour.
+	idx map[uint32]uint8
+
+	img *image.Paletted
+	buf bytes.Buffer
+	hdr bool
+}
+
+// newGIFWriter returns a writer that writes an animated GIF with the given
+// palette to f.
+func newGIFWriter(f *os.File, pal []uint32) *gifWriter {
+	g := &gifWriter{
+		f:   f,
+		w:   bufio.NewWriter(f),
+		idx: make(map[uint32]uint8, len(pal)),
+	}
+	for i, c := range pal {
+		g.pal = append(g.pal, color.RGBA{uint8(c >> 16), uint8(c >> 8), uint8(c), 0xff})
+		g.idx[c] = uint8(i)
+	}
+
+	return g
+}
+
+// WriteFrame implements frameWriter.
+func (g *gifWriter) WriteFrame(img *image.RGBA, n int) error {
+	if n%gifFrameSkip != 0 {
+		return nil
+	}
+
+	if g.img == nil || g.img.Rect != img.Rect {
+		g.img = image.NewPaletted(img.Rect, g.pal)
+	}
+	for i, p := 0, 0; p < len(img.Pix); i, p = i+1, p+4 {
+		g.img.Pix[i] = g.idx[pack(img.Pix[p], img.Pix[p+1], img.Pix[p+2])]
+	}
+
+	// The delay is the time until the next GIF frame, in 1/100 second,
+	// rounded so that the rounding errors do not accumulate.
+	k := int64(n / gifFrameSkip)
+	delay := framesToUnits((k+1)*gifFrameSkip, 100) - framesToUnits(k*gifFrameSkip, 100)
+
+	g.buf.Reset()
+	err := gif.EncodeAll(&g.buf, &gif.GIF{
+		Image: []*image.Paletted{g.img},
+		Delay: []int{int(delay)},
+		Config: image.Config{
+			ColorModel: g.pal,
+			Width:      img.Rect.Dx(),
+			Height:     img.Rect.Dy(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	b := g.buf.Bytes()
+
+	// The header is the signature, the logical screen descriptor and the
+	// global colour table, if there is one.
+	hl := 13
+	if b[10]&0x80 != 0 {
+		hl += 3 << ((b[10] & 0x07) + 1)
+	}
+
+	if !g.hdr {
+		if _, err = g.w.Write(b[:hl]); err != nil {
+			return err
+		}
+		if _, err = g.w.Write(netscapeLoop); err != nil {
+			return err
+		}
+		g.hdr = true
+	}
+
+	// Write the frame, without the trailer.
+	_, err = g.w.Write(b[hl : len(b)-1])
+	return err
+}
+
+// Close implements frameWriter.
+func (g *gifWriter) Close() error {
+	if g.hdr {
+		if err := g.w.WriteByte(0x3b); err != nil {
+			g.f.Close()
+			return err
+		}
+	}
+	if err := g.w.Flush(); err != nil {
+		g.f.Close()
+		return err
+	}
+
+	return g.f.Close()
+}
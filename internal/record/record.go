@@ -0,0 +1,191 @@
+// Package record records the emulated frames and sound of the machine to video
+// files.
+package record
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danmrichards/go-invaders/internal/machine"
+	"github.com/danmrichards/go-invaders/internal/overlay"
+	"github.com/danmrichards/go-invaders/internal/sound"
+	"github.com/danmrichards/go-invaders/internal/video"
+)
+
+type (
+	// Recorder records video to an animated GIF, or to a Y4M video with a
+	// WAV of the sound alongside.
+	//
+	// Recorder follows the emulated timeline: every emulated frame is
+	// recorded at the frame rate of the original machine, however fast the
+	// emulation runs.
+	Recorder struct {
+		// The colour overlay and integer scale factor of the recorded
+		// frames.
+		ov    *overlay.Overlay
+		scale int
+
+		// The decoded screen and the scaling filter.
+		img *image.RGBA
+		f   video.Pipeline
+
+		// The video file, and the number of frames recorded to it.
+		fw frameWriter
+		n  int
+
+		// The mixer that renders the sound track, and the sink writing it,
+		// nil if there is none.
+		mx *sound.Mixer
+		at *sound.WAV
+	}
+
+	// Option is a functional option that modifies a field on the recorder.
+	Option func(*Recorder)
+
+	// frameWriter writes frames to a video file.
+	//
+	// WriteFrame writes the frame with the given index on the emulated
+	// timeline.
+	frameWriter interface {
+		WriteFrame(img *image.RGBA, n int) error
+		Close() error
+	}
+)
+
+// WithOverlay sets the colour overlay of the recorded frames.
+func WithOverlay(o *overlay.Overlay) Option {
+	return func(r *Recorder) {
+		r.ov = o
+	}
+}
+
+// WithScale sets the integer scale factor of the recorded frames.
+func WithScale(scale int) Option {
+	return func(r *Recorder) {
+		r.scale = scale
+	}
+}
+
+// WithSound sets the mixer that renders the WAV recorded alongside a Y4M
+// video. The mixer must not be shared with any other sink.
+func WithSound(mx *sound.Mixer) Option {
+	return func(r *Recorder) {
+		r.mx = mx
+	}
+}
+
+// New returns a recorder that records to the file at path. The format is
+// chosen by the file extension: ".gif" for an animated GIF or ".y4m" for a
+// Y4M video. If a mixer is supplied, a Y4M video is accompanied by a
+// WAV of the sound with the same name and the extension ".wav".
+func New(path string, opts ...Option) (r *Recorder, err error) {
+	r = &Recorder{
+		scale: 1,
+		img:   video.NewImage(),
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	if r.scale > 1 {
+		if r.f, err = video.ParseFilters(fmt.Sprintf("nearest:%d", r.scale)); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".gif":
+		r.fw = newGIFWriter(f, r.palette())
+	case ".y4m":
+		r.fw = newY4MWriter(f)
+		if r.mx != nil {
+			wp := strings.TrimSuffix(path, filepath.Ext(path)) + ".wav"
+			if r.at, err = sound.NewWAV(wp, r.mx); err != nil {
+				f.Close()
+				return nil, err
+			}
+		}
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unsupported video format %q: must be .gif or .y4m", ext)
+	}
+
+	return r, nil
+}
+
+// HasSound returns true if a recording to the file at path records sound, as
+// Y4M videos do and animated GIFs do not. It lets the caller skip building a
+// mixer that would go unused.
+func HasSound(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".y4m")
+}
+
+// Frame implements machine.Recorder.
+func (r *Recorder) Frame(s machine.Screen) error {
+	video.Decode(r.img, s.VRAM(), r.ov)
+	if err := r.fw.WriteFrame(r.f.Apply(r.img), r.n); err != nil {
+		return err
+	}
+	r.n++
+
+	if r.at != nil {
+		return r.at.Frame()
+	}
+
+	return nil
+}
+
+// Sound implements machine.Recorder.
+func (r *Recorder) Sound(port, data byte, cycle int) {
+	if r.at != nil {
+		r.at.Out(port, data, cycle)
+	}
+}
+
+// Close implements machine.Recorder.
+func (r *Recorder) Close() error {
+	err := r.fw.Close()
+	if r.at != nil {
+		if aerr := r.at.Close(); err == nil {
+			err = aerr
+		}
+	}
+
+	return err
+}
+
+// palette returns the colours of the recorded frames: black for unlit pixels
+// followed by the colours of the overlay.
+func (r *Recorder) palette() []uint32 {
+	pal := []uint32{pack(0, 0, 0)}
+	if r.ov == nil {
+		return append(pal, pack(0xff, 0xff, 0xff))
+	}
+
+	for _, c := range r.ov.Colors() {
+		pal = append(pal, pack(c.R, c.G, c.B))
+	}
+
+	return pal
+}
+
+// pack returns the colour packed into a single value.
+func pack(r, g, b uint8) uint32 {
+	return uint32(r)<<16 | uint32(g)<<8 | uint32(b)
+}
+
+// framesToUnits converts a number of emulated frames into a number of time
+// units, of which there are perSecond per second, rounded to the nearest
+// unit.
+func framesToUnits(frames int64, perSecond int) int64 {
+	return (frames*int64(machine.CyclesPerFrame)*int64(perSecond) + machine.ClockSpeed/2) / machine.ClockSpeed
+}
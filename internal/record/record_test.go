@@ -0,0 +1,299 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"image/gif"
+	"io"
+	"io/ioutil"
+	"math/bits"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danmrichards/go-invaders/internal/overlay"
+	"github.com/danmrichards/go-invaders/internal/sound"
+	"github.com/danmrichards/go-invaders/internal/video"
+)
+
+// testScreen is a screen of the given video RAM contents.
+type testScreen []byte
+
+// Draw implements machine.Screen.
+func (s testScreen) Draw(func(x, y int)) {}
+
+// VRAM implements machine.Screen.
+func (s testScreen) VRAM() []byte { return s }
+
+// testScreens returns n screens of random video RAM contents.
+func testScreens(n int) []testScreen {
+	r := rand.New(rand.NewSource(1))
+
+	screens := make([]testScreen, n)
+	for i := range screens {
+		screens[i] = make(testScreen, video.VRAMSize)
+		r.Read(screens[i])
+	}
+
+	return screens
+}
+
+// lit returns the number of lit pixels on the screen.
+func (s testScreen) lit() (n int) {
+	for _, b := range s {
+		n += bits.OnesCount8(b)
+	}
+
+	return n
+}
+
+// record records the screens to the file at path, and returns its contents.
+func record(t *testing.T, path string, screens []testScreen, opts ...Option) []byte {
+	t.Helper()
+
+	r, err := New(path, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range screens {
+		if err = r.Frame(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+// tempDir returns a temporary directory, removed when the test ends.
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+func TestGIF(t *testing.T) {
+	ov, err := overlay.Load("upright")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The palette is black followed by the overlay colours.
+	opal := color.Palette{color.RGBA{0, 0, 0, 0xff}}
+	for _, c := range ov.Colors() {
+		opal = append(opal, color.RGBA{c.R, c.G, c.B, 0xff})
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		pal  color.Palette
+	}{
+		{"monochrome", nil, color.Palette{color.RGBA{0, 0, 0, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}}},
+		{"overlay", []Option{WithOverlay(ov)}, opal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only every other frame is recorded.
+			screens := testScreens(7)
+			b := record(t, filepath.Join(tempDir(t), "out.gif"), screens, tt.opts...)
+
+			g, err := gif.DecodeAll(bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(g.Image) != 4 {
+				t.Fatalf("%d frames, want 4", len(g.Image))
+			}
+			if g.LoopCount != 0 {
+				t.Errorf("loop count = %d, want 0 to loop forever", g.LoopCount)
+			}
+			if g.Config.Width != video.Width || g.Config.Height != video.Height {
+				t.Errorf("size = %dx%d, want %dx%d", g.Config.Width, g.Config.Height, video.Width, video.Height)
+			}
+
+			// Each delay is 3 or 4 hundredths of a second, and together they
+			// add up to the time taken by the frames, so that the video keeps
+			// time with the machine.
+			var total int
+			for i, d := range g.Delay {
+				if d != 3 && d != 4 {
+					t.Errorf("frame %d: delay = %d, want 3 or 4", i, d)
+				}
+				total += d
+			}
+			if want := int(framesToUnits(8, 100)); total != want {
+				t.Errorf("total delay = %d, want %d", total, want)
+			}
+
+			for i, img := range g.Image {
+				pal := img.Palette
+				if len(pal) > len(tt.pal) {
+					pal = pal[:len(tt.pal)]
+				}
+				if !equalPalettes(pal, tt.pal) {
+					t.Errorf("frame %d: palette = %v, want %v", i, img.Palette, tt.pal)
+				}
+
+				var lit int
+				for _, p := range img.Pix {
+					if p != 0 {
+						lit++
+					}
+				}
+				if want := screens[i*gifFrameSkip].lit(); lit != want {
+					t.Errorf("frame %d: %d pixels lit, want %d", i, lit, want)
+				}
+			}
+		})
+	}
+}
+
+// equalPalettes returns true if the palettes hold the same colours.
+func equalPalettes(a, b color.Palette) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		r1, g1, b1, a1 := a[i].RGBA()
+		r2, g2, b2, a2 := b[i].RGBA()
+		if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestY4M(t *testing.T) {
+	for _, scale := range []int{1, 2} {
+		t.Run(fmt.Sprintf("scale %d", scale), func(t *testing.T) {
+			screens := testScreens(5)
+			b := record(t, filepath.Join(tempDir(t), "out.y4m"), screens, WithScale(scale))
+
+			br := bufio.NewReader(bytes.NewReader(b))
+			hdr, err := br.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The frame rate is that of the machine, 7800/131 or about
+			// 59.54Hz, in lowest terms.
+			w, h := video.Width*scale, video.Height*scale
+			want := fmt.Sprintf("YUV4MPEG2 W%d H%d F7800:131 Ip A1:1 C444 XCOLORRANGE=FULL\n", w, h)
+			if hdr != want {
+				t.Errorf("header = %q, want %q", hdr, want)
+			}
+
+			frame := make([]byte, w*h*3)
+			for i, s := range screens {
+				tag, err := br.ReadString('\n')
+				if err != nil {
+					t.Fatalf("frame %d: %v", i, err)
+				}
+				if tag != "FRAME\n" {
+					t.Fatalf("frame %d: tag = %q, want %q", i, tag, "FRAME\n")
+				}
+				if _, err = io.ReadFull(br, frame); err != nil {
+					t.Fatalf("frame %d: %v", i, err)
+				}
+
+				// The luma plane is full range white for lit pixels and black
+				// for the rest, with neutral chroma.
+				var lit int
+				for _, y := range frame[:w*h] {
+					switch y {
+					case 0xff:
+						lit++
+					case 0x00:
+					default:
+						t.Fatalf("frame %d: luma %02x, want ff or 00", i, y)
+					}
+				}
+				if want := s.lit() * scale * scale; lit != want {
+					t.Errorf("frame %d: %d pixels lit, want %d", i, lit, want)
+				}
+				for _, c := range frame[w*h:] {
+					if c != 0x80 {
+						t.Fatalf("frame %d: chroma %02x, want 80", i, c)
+					}
+				}
+			}
+
+			if n, _ := br.Read(make([]byte, 1)); n != 0 {
+				t.Error("data after the last frame")
+			}
+		})
+	}
+}
+
+func TestSound(t *testing.T) {
+	dir := tempDir(t)
+
+	mx, err := sound.NewMixer(sound.NewSynth(), sound.DefaultMixerSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The WAV has the length of the frames recorded.
+	const frames = 6
+	record(t, filepath.Join(dir, "out.y4m"), testScreens(frames), WithSound(mx))
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "out.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) < 44 || string(b[:4]) != "RIFF" || string(b[36:40]) != "data" {
+		t.Fatal("not a WAV file")
+	}
+	want := uint32(sound.FrameSamples(frames, mx.Rate()) * 2)
+	if size := binary.LittleEndian.Uint32(b[40:]); size != want {
+		t.Errorf("data size = %d, want %d", size, want)
+	}
+
+	// A GIF has no sound, so no WAV is written alongside.
+	record(t, filepath.Join(dir, "anim.gif"), testScreens(frames), WithSound(mx))
+	if _, err = os.Stat(filepath.Join(dir, "anim.wav")); !os.IsNotExist(err) {
+		t.Errorf("WAV written alongside a GIF: %v", err)
+	}
+}
+
+func TestHasSound(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"out.y4m", true},
+		{"dir/OUT.Y4M", true},
+		{"out.gif", false},
+		{"out", false},
+	}
+	for _, tt := range tests {
+		if got := HasSound(tt.path); got != tt.want {
+			t.Errorf("HasSound(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestNewUnsupported(t *testing.T) {
+	if _, err := New(filepath.Join(tempDir(t), "out.mp4")); err == nil {
+		t.Error("expected an error recording to an unsupported format")
+	}
+}
@@ -0,0 +1,74 @@
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+
+	"github.com/danmrichards/go-invaders/internal/machine"
+)
+
+// y4mWriter writes an uncompressed YUV4MPEG2 video, with full range 4:4:4
+// chroma, at the exact frame rate of the machine.
+type y4mWriter struct {
+	f   *os.File
+	w   *bufio.Writer
+	buf []byte
+	hdr bool
+}
+
+// newY4MWriter returns a writer that writes a Y4M video to f.
+func newY4MWriter(f *os.File) *y4mWriter {
+	return &y4mWriter{
+		f: f,
+		w: bufio.NewWriter(f),
+	}
+}
+
+// WriteFrame implements frameWriter.
+func (y *y4mWriter) WriteFrame(img *image.RGBA, _ int) error {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	if !y.hdr {
+		// The frame rate as an exact fraction, in lowest terms.
+		num, den := machine.ClockSpeed, machine.CyclesPerFrame
+		a, b := num, den
+		for b != 0 {
+			a, b = b, a%b
+		}
+		num, den = num/a, den/a
+
+		if _, err := fmt.Fprintf(
+			y.w, "YUV4MPEG2 W%d H%d F%d:%d Ip A1:1 C444 XCOLORRANGE=FULL\n",
+			w, h, num, den,
+		); err != nil {
+			return err
+		}
+		y.hdr = true
+	}
+
+	if len(y.buf) != w*h*3 {
+		y.buf = make([]byte, w*h*3)
+	}
+	yp, cbp, crp := y.buf[:w*h], y.buf[w*h:2*w*h], y.buf[2*w*h:]
+	for i, p := 0, 0; p < len(img.Pix); i, p = i+1, p+4 {
+		yp[i], cbp[i], crp[i] = color.RGBToYCbCr(img.Pix[p], img.Pix[p+1], img.Pix[p+2])
+	}
+
+	if _, err := y.w.WriteString("FRAME\n"); err != nil {
+		return err
+	}
+	_, err := y.w.Write(y.buf)
+	return err
+}
+
+// Close implements frameWriter.
+func (y *y4mWriter) Close() error {
+	if err := y.w.Flush(); err != nil {
+		y.f.Close()
+		return err
+	}
+
+	return y.f.Close()
+}
@@ -0,0 +1,198 @@
+package sound
+
+import (
+	"fmt"
+)
+
+// Channel is a sound channel of the sound board, driven by a single bit of
+// sound port 3 or 5.
+type Channel int
+
+// The sound channels of the sound board, in the order of the bits of port 3
+// then port 5.
+const (
+	ChannelUFO Channel = iota
+	ChannelShot
+	ChannelPlayerDie
+	ChannelInvaderDie
+	ChannelExtendedPlay
+	ChannelFleet1
+	ChannelFleet2
+	ChannelFleet3
+	ChannelFleet4
+	ChannelUFOHit
+
+	// NumChannels is the number of sound channels.
+	NumChannels
+)
+
+type (
+	// channel describes a sound channel.
+	channel struct {
+		port byte
+		bit  uint
+
+		// The name of the channel, as used in configuration, and the name of
+		// the sample it plays, which follows the MAME sample naming.
+		name   string
+		sample string
+
+		// True if the sample loops for as long as the bit is set. Other
+		// samples play once each time the bit is set.
+		loop bool
+
+		// True if the sample stops as soon as the bit is cleared, as the
+		// sound board only makes the sound while the bit is set. Other
+		// samples play to the end.
+		gated bool
+	}
+
+	// Sample holds a single sample of every sound channel, from -1 to 1.
+	Sample [NumChannels]float64
+
+	// Latch models the sound latches of ports 3 and 5, which drive the sound
+	// channels, and plays the samples of each channel.
+	//
+	// Each channel runs independently: setting its bit starts its sample from
+	// the beginning, and clearing its bit stops the sample of a gated channel,
+	// such as the UFO loop and the extended play chime. All output is muted
+	// while the amplifier is disabled by bit 5 of port 3, as it is during the
+	// attract mode.
+	//
+	// Channels without a sample are played by the sound circuit of the
+	// synthesiser instead. There is no embedded extended play sample, so the
+	// extended play chime is synthesised unless a sample pack has 9.wav.
+	Latch struct {
+		// The samples of each channel, nil if the channel has no sample.
+		smps [NumChannels][]float64
+
+		// The synthesiser that plays the channels without a sample.
+		synth *Synth
+
+		// The state of the bit driving each channel, whether its sample is
+		// playing and the position of the next sample.
+		bits    [NumChannels]bool
+		playing [NumChannels]bool
+		pos     [NumChannels]int
+
+		// Whether the amplifier is enabled.
+		amp bool
+	}
+)
+
+// The amplifier enable bit of port 3.
+const ampEnable = 0x01 << 5
+
+// channels describes every sound channel.
+var channels = [NumChannels]channel{
+	ChannelUFO:          {port: 0x03, bit: 0, name: "ufo", sample: "0.wav", loop: true, gated: true},
+	ChannelShot:         {port: 0x03, bit: 1, name: "shot", sample: "1.wav"},
+	ChannelPlayerDie:    {port: 0x03, bit: 2, name: "player-die", sample: "2.wav"},
+	ChannelInvaderDie:   {port: 0x03, bit: 3, name: "invader-die", sample: "3.wav"},
+	ChannelExtendedPlay: {port: 0x03, bit: 4, name: "extended-play", sample: "9.wav", gated: true},
+	ChannelFleet1:       {port: 0x05, bit: 0, name: "fleet-1", sample: "4.wav"},
+	ChannelFleet2:       {port: 0x05, bit: 1, name: "fleet-2", sample: "5.wav"},
+	ChannelFleet3:       {port: 0x05, bit: 2, name: "fleet-3", sample: "6.wav"},
+	ChannelFleet4:       {port: 0x05, bit: 3, name: "fleet-4", sample: "7.wav"},
+	ChannelUFOHit:       {port: 0x05, bit: 4, name: "ufo-hit", sample: "8.wav"},
+}
+
+// Channels returns every sound channel.
+func Channels() []Channel {
+	cs := make([]Channel, NumChannels)
+	for i := range cs {
+		cs[i] = Channel(i)
+	}
+
+	return cs
+}
+
+// ParseChannel returns the sound channel with the given name.
+func ParseChannel(name string) (Channel, error) {
+	for c, ch := range channels {
+		if ch.name == name {
+			return Channel(c), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown sound channel %q", name)
+}
+
+// String returns the name of the channel, as used in configuration.
+func (c Channel) String() string {
+	if c < 0 || c >= NumChannels {
+		return fmt.Sprintf("Channel(%d)", int(c))
+	}
+
+	return channels[c].name
+}
+
+// NewLatch returns a sound latch that plays the given samples, keyed by sample
+// name. Channels without a sample are synthesised.
+func NewLatch(smps map[string][]float64) *Latch {
+	l := &Latch{synth: NewSynth()}
+	for i, c := range channels {
+		l.smps[i] = smps[c.sample]
+	}
+
+	return l
+}
+
+// Out implements Source.
+func (l *Latch) Out(port, data byte) {
+	l.synth.Out(port, data)
+
+	if port == 0x03 {
+		l.amp = data&ampEnable != 0
+	}
+
+	for i, c := range channels {
+		if c.port != port {
+			continue
+		}
+
+		bit := data&(0x01<<c.bit) != 0
+		switch {
+		case bit && !l.bits[i]:
+			l.playing[i], l.pos[i] = true, 0
+		case !bit && c.gated:
+			l.playing[i] = false
+		}
+		l.bits[i] = bit
+	}
+}
+
+// Mix implements Source.
+func (l *Latch) Mix(out []Sample) {
+	for n := range out {
+		out[n] = Sample{}
+		for i, c := range channels {
+			smps := l.smps[i]
+			if smps == nil {
+				if sc := l.synth.cs[i]; sc.playing {
+					if cv := sc.next(l.synth); l.amp {
+						out[n][i] = cv
+					}
+				}
+				continue
+			}
+
+			if !l.playing[i] {
+				continue
+			}
+
+			if l.pos[i] >= len(smps) {
+				if !c.loop || len(smps) == 0 {
+					l.playing[i] = false
+					continue
+				}
+				l.pos[i] = 0
+			}
+
+			if l.amp {
+				out[n][i] = smps[l.pos[i]]
+			}
+			l.pos[i]++
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package sound
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// The range of the master and channel volumes.
+	minVolume, maxVolume = 0, 2
+
+	// The range of output sample rates, in Hz.
+	minRate, maxRate = SampleRate, 192000
+
+	// The level above which the limiter starts to compress the output.
+	limitThreshold = 0.8
+)
+
+type (
+	// MixerSettings are the settings of the mixer.
+	MixerSettings struct {
+		// The output sample rate, in Hz, which should match the rate of the
+		// audio device.
+		Rate int `json:"rate"`
+
+		// The master volume, from 0 to 2, and whether all sound is muted.
+		Volume float64 `json:"volume"`
+		Mute   bool    `json:"mute"`
+
+		// The volume of each channel, from 0 to 2, keyed by channel name.
+		// Channels that are not listed play at a volume of 1.
+		Channels map[string]float64 `json:"channels,omitempty"`
+
+		// The names of the muted channels.
+		Muted []string `json:"muted,omitempty"`
+	}
+
+	// Mixer mixes the sound channels of a sound engine into a single output,
+	// resampled to the output sample rate.
+	//
+	// Each channel has its own volume and can be muted, and the mix has a
+	// master volume. A soft limiter keeps loud mixes from clipping harshly.
+	//
+	// Mixer is safe for concurrent use, so the settings may be changed while
+	// the output is played.
+	Mixer struct {
+		mu  sync.Mutex
+		src Source
+
+		// The output sample rate, the master volume and the volume of each
+		// channel, and whether they are muted.
+		rate   int
+		volume float64
+		mute   bool
+		vols   [NumChannels]float64
+		mutes  [NumChannels]bool
+
+		// The number of source samples per output sample.
+		step float64
+
+		// The 4 mixed source samples around the output position, which lies
+		// frac of the way from hist[1] to hist[2].
+		hist [4]float64
+		frac float64
+		smp  [1]Sample
+	}
+)
+
+// DefaultMixerSettings returns the default mixer settings.
+func DefaultMixerSettings() MixerSettings {
+	return MixerSettings{
+		Rate:   44100,
+		Volume: 1,
+	}
+}
+
+// Validate returns an error if any setting is invalid.
+func (s MixerSettings) Validate() error {
+	if s.Rate < minRate || s.Rate > maxRate {
+		return fmt.Errorf("invalid sample rate %d: must be %d to %d", s.Rate, minRate, maxRate)
+	}
+	if err := validVolume(s.Volume); err != nil {
+		return err
+	}
+
+	for name, v := range s.Channels {
+		if _, err := ParseChannel(name); err != nil {
+			return err
+		}
+		if err := validVolume(v); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for _, name := range s.Muted {
+		if _, err := ParseChannel(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validVolume returns an error if v is not a valid volume.
+func validVolume(v float64) error {
+	if v < minVolume || v > maxVolume {
+		return fmt.Errorf("invalid volume %g: must be %d to %d", v, minVolume, maxVolume)
+	}
+
+	return nil
+}
+
+// NewMixer returns a mixer of the output of the given sound engine, with the
+// given settings.
+func NewMixer(src Source, s MixerSettings) (*Mixer, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := &Mixer{
+		src:    src,
+		rate:   s.Rate,
+		volume: s.Volume,
+		mute:   s.Mute,
+		step:   float64(SampleRate) / float64(s.Rate),
+	}
+	for i := range m.vols {
+		m.vols[i] = 1
+	}
+	for name, v := range s.Channels {
+		c, _ := ParseChannel(name)
+		m.vols[c] = v
+	}
+	for _, name := range s.Muted {
+		c, _ := ParseChannel(name)
+		m.mutes[c] = true
+	}
+
+	return m, nil
+}
+
+// Rate returns the output sample rate, in Hz.
+func (m *Mixer) Rate() int {
+	return m.rate
+}
+
+// SetRatio scales the number of samples mixed for each second of sound by r,
+// which must be close to 1, slightly lowering or raising the pitch. It is used
+// to adjust the output rate to the rate the sound is played at.
+func (m *Mixer) SetRatio(r float64) {
+	m.mu.Lock()
+	m.step = float64(SampleRate) / (float64(m.rate) * r)
+	m.mu.Unlock()
+}
+
+// Settings returns the current settings of the mixer.
+func (m *Mixer) Settings() MixerSettings {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := MixerSettings{
+		Rate:   m.rate,
+		Volume: m.volume,
+		Mute:   m.mute,
+	}
+	for c, v := range m.vols {
+		if v != 1 {
+			if s.Channels == nil {
+				s.Channels = make(map[string]float64)
+			}
+			s.Channels[Channel(c).String()] = v
+		}
+		if m.mutes[c] {
+			s.Muted = append(s.Muted, Channel(c).String())
+		}
+	}
+	sort.Strings(s.Muted)
+
+	return s
+}
+
+// Volume implements machine.Mixer.
+func (m *Mixer) Volume() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.volume
+}
+
+// SetVolume implements machine.Mixer.
+func (m *Mixer) SetVolume(v float64) {
+	m.mu.Lock()
+	m.volume = math.Max(minVolume, math.Min(maxVolume, v))
+	m.mu.Unlock()
+}
+
+// Muted implements machine.Mixer.
+func (m *Mixer) Muted() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.mute
+}
+
+// SetMuted implements machine.Mixer.
+func (m *Mixer) SetMuted(muted bool) {
+	m.mu.Lock()
+	m.mute = muted
+	m.mu.Unlock()
+}
+
+// Set implements machine.Mixer. The name is "volume" for the master volume or
+// the name of a channel, and the value is a volume from 0 to 2, or on or off
+// to unmute or mute.
+func (m *Mixer) Set(name, value string) error {
+	vol, mute := &m.volume, &m.mute
+	if name = strings.ToLower(name); name != "volume" {
+		c, err := ParseChannel(name)
+		if err != nil {
+			return err
+		}
+		vol, mute = &m.vols[c], &m.mutes[c]
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch strings.ToLower(value) {
+	case "on":
+		*mute = false
+	case "off":
+		*mute = true
+	default:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %q: must be a volume, on or off", value)
+		}
+		if err = validVolume(v); err != nil {
+			return err
+		}
+		*vol = v
+	}
+
+	return nil
+}
+
+// String implements machine.Mixer.
+func (m *Mixer) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ss := []string{"volume=" + level(m.volume, m.mute)}
+	for c, v := range m.vols {
+		ss = append(ss, Channel(c).String()+"="+level(v, m.mutes[c]))
+	}
+
+	return strings.Join(ss, " ")
+}
+
+// level returns the volume, or off if it is muted.
+func level(v float64, muted bool) string {
+	if muted {
+		return "off"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Out latches a value written to sound port 3 or 5.
+func (m *Mixer) Out(port, data byte) {
+	m.mu.Lock()
+	m.src.Out(port, data)
+	m.mu.Unlock()
+}
+
+// Mix fills out with the next samples of the mixed output, from -1 to 1, at
+// the output sample rate.
+//
+// The output is interpolated between the mixed samples of the engine with a
+// cubic Hermite spline, which keeps the images of the low sample rate of the
+// engine out of the audible output, and looks only 2 samples ahead, so that
+// values written to the sound ports take effect without delay.
+func (m *Mixer) Mix(out []float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range out {
+		h := m.hist
+		out[i] = limit(hermite(h[0], h[1], h[2], h[3], m.frac))
+
+		for m.frac += m.step; m.frac >= 1; m.frac-- {
+			m.hist = [4]float64{h[1], h[2], h[3], m.next()}
+			h = m.hist
+		}
+	}
+}
+
+// next returns the next sample of the engine, mixed by the channel and master
+// volumes.
+func (m *Mixer) next() float64 {
+	m.src.Mix(m.smp[:])
+	if m.mute {
+		return 0
+	}
+
+	var v float64
+	for c, s := range m.smp[0] {
+		if !m.mutes[c] {
+			v += s * m.vols[c]
+		}
+	}
+
+	return v * m.volume
+}
+
+// hermite returns the value a fraction t of the way between y1 and y2 on the
+// Catmull-Rom spline through y0 to y3.
+func hermite(y0, y1, y2, y3, t float64) float64 {
+	c1 := (y2 - y0) / 2
+	c2 := y0 - 2.5*y1 + 2*y2 - y3/2
+	c3 := (y3-y0)/2 + 1.5*(y1-y2)
+
+	return ((c3*t+c2)*t+c1)*t + y1
+}
+
+// limit returns v passed through the soft limiter, which leaves levels below
+// limitThreshold untouched and smoothly compresses louder levels to within -1
+// to 1.
+func limit(v float64) float64 {
+	a := math.Abs(v)
+	if a <= limitThreshold {
+		return v
+	}
+
+	const knee = 1 - limitThreshold
+	return math.Copysign(limitThreshold+knee*math.Tanh((a-limitThreshold)/knee), v)
+}
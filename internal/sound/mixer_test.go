@@ -0,0 +1,208 @@
+package sound
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// testSource is a sound engine that plays the same sample of every channel
+// for ever, and counts the samples mixed.
+type testSource struct {
+	smp Sample
+	n   int
+}
+
+// Out implements Source.
+func (s *testSource) Out(port, data byte) {}
+
+// Mix implements Source.
+func (s *testSource) Mix(out []Sample) {
+	for i := range out {
+		out[i] = s.smp
+	}
+	s.n += len(out)
+}
+
+func TestMixerSettingsValidate(t *testing.T) {
+	def := DefaultMixerSettings()
+
+	tests := []struct {
+		name  string
+		edit  func(s *MixerSettings)
+		valid bool
+	}{
+		{"default", func(s *MixerSettings) {}, true},
+		{"lowest rate", func(s *MixerSettings) { s.Rate = SampleRate }, true},
+		{"highest rate", func(s *MixerSettings) { s.Rate = 192000 }, true},
+		{"rate too low", func(s *MixerSettings) { s.Rate = SampleRate - 1 }, false},
+		{"rate too high", func(s *MixerSettings) { s.Rate = 192001 }, false},
+		{"silent", func(s *MixerSettings) { s.Volume = 0 }, true},
+		{"loudest", func(s *MixerSettings) { s.Volume = 2 }, true},
+		{"negative volume", func(s *MixerSettings) { s.Volume = -0.1 }, false},
+		{"volume too high", func(s *MixerSettings) { s.Volume = 2.1 }, false},
+		{"channel volume", func(s *MixerSettings) { s.Channels = map[string]float64{"ufo": 0.5} }, true},
+		{"channel volume too high", func(s *MixerSettings) { s.Channels = map[string]float64{"ufo": 3} }, false},
+		{"unknown channel", func(s *MixerSettings) { s.Channels = map[string]float64{"laser": 1} }, false},
+		{"muted channel", func(s *MixerSettings) { s.Muted = []string{"shot"} }, true},
+		{"unknown muted channel", func(s *MixerSettings) { s.Muted = []string{"laser"} }, false},
+	}
+	for _, tt := range tests {
+		s := def
+		tt.edit(&s)
+
+		err := s.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+
+		if _, err = NewMixer(&testSource{}, s); (err == nil) != tt.valid {
+			t.Errorf("%s: NewMixer error = %v, want valid %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestMixerSet(t *testing.T) {
+	m, err := NewMixer(&testSource{}, DefaultMixerSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, set := range [][2]string{
+		{"volume", "0.5"},
+		{"UFO", "1.5"},
+		{"shot", "off"},
+		{"player-die", "off"},
+		{"player-die", "on"},
+		{"volume", "off"},
+	} {
+		if err = m.Set(set[0], set[1]); err != nil {
+			t.Errorf("Set(%q, %q): %v", set[0], set[1], err)
+		}
+	}
+
+	want := MixerSettings{
+		Rate:     44100,
+		Volume:   0.5,
+		Mute:     true,
+		Channels: map[string]float64{"ufo": 1.5},
+		Muted:    []string{"shot"},
+	}
+	if got := m.Settings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("settings = %+v, want %+v", got, want)
+	}
+
+	for _, set := range [][2]string{
+		{"volume", "2.5"},
+		{"volume", "-1"},
+		{"volume", "loud"},
+		{"ufo", ""},
+		{"laser", "1"},
+	} {
+		if err = m.Set(set[0], set[1]); err == nil {
+			t.Errorf("Set(%q, %q): expected an error", set[0], set[1])
+		}
+	}
+	if got := m.Settings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("settings changed by invalid values: %+v", got)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	prev := math.Inf(-1)
+	for v := -10.0; v <= 10; v += 0.01 {
+		l := limit(v)
+		if l < -1 || l > 1 {
+			t.Fatalf("limit(%g) = %g, outside -1 to 1", v, l)
+		}
+		if math.Abs(v) <= limitThreshold && l != v {
+			t.Fatalf("limit(%g) = %g, want it unchanged below the threshold", v, l)
+		}
+		if l < prev {
+			t.Fatalf("limit(%g) = %g, below limit of a lower level %g", v, l, prev)
+		}
+		prev = l
+	}
+}
+
+func TestMixerLimits(t *testing.T) {
+	// Every channel at full level and double volume sums to 20.
+	src := &testSource{}
+	for i := range src.smp {
+		src.smp[i] = 1
+	}
+	s := DefaultMixerSettings()
+	s.Volume = 2
+	m, err := NewMixer(src, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make([]float64, 1000)
+	m.Mix(out)
+	for i, v := range out {
+		if v < -1 || v > 1 {
+			t.Fatalf("sample %d = %g, outside -1 to 1", i, v)
+		}
+	}
+}
+
+func TestMixerRate(t *testing.T) {
+	for _, rate := range []int{SampleRate, 22050, 44100, 48000, 192000} {
+		src := &testSource{}
+		s := DefaultMixerSettings()
+		s.Rate = rate
+		m, err := NewMixer(src, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A second of output takes a second of source samples, give or take
+		// the samples buffered for interpolation.
+		out := make([]float64, rate)
+		m.Mix(out)
+		if src.n < SampleRate-2 || src.n > SampleRate+2 {
+			t.Errorf("%d Hz: mixed %d source samples for a second of output, want %d", rate, src.n, SampleRate)
+		}
+	}
+}
+
+func TestMixerChannels(t *testing.T) {
+	tests := []struct {
+		name string
+		s    func(s *MixerSettings)
+		want float64
+	}{
+		{"all", func(s *MixerSettings) {}, 0.3},
+		{"muted", func(s *MixerSettings) { s.Muted = []string{"ufo"} }, 0.2},
+		{"both muted", func(s *MixerSettings) { s.Muted = []string{"ufo", "shot"} }, 0},
+		{"channel volume", func(s *MixerSettings) { s.Channels = map[string]float64{"ufo": 2} }, 0.4},
+		{"master volume", func(s *MixerSettings) { s.Volume = 0.5 }, 0.15},
+		{"master mute", func(s *MixerSettings) { s.Mute = true }, 0},
+	}
+	for _, tt := range tests {
+		src := &testSource{}
+		src.smp[ChannelUFO], src.smp[ChannelShot] = 0.1, 0.2
+
+		s := DefaultMixerSettings()
+		tt.s(&s)
+		m, err := NewMixer(src, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Skip the samples interpolated from the silence before the source
+		// started.
+		out := make([]float64, 100)
+		m.Mix(out)
+		for i, v := range out[20:] {
+			if math.Abs(v-tt.want) > 1e-9 {
+				t.Errorf("%s: sample %d = %g, want %g", tt.name, i+20, v, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package sound
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// manifestName is the name of the manifest of a sample pack.
+const manifestName = "manifest.json"
+
+// samplePack is a directory or zip archive of samples.
+type samplePack struct {
+	// The slash separated path of every file in the pack, relative to its
+	// root, sorted.
+	files []string
+
+	// Returns the contents of the named file.
+	read func(name string) ([]byte, error)
+
+	// Releases any resources held by the pack.
+	close func() error
+}
+
+// LoadSamples returns the decoded mono samples of every sound, from -1 to 1,
+// keyed by name, with the samples of the pack at path replacing the embedded
+// samples.
+//
+// The pack may be a directory or a zip archive, such as MAME's
+// samples/invaders.zip. If the pack contains a manifest.json, it maps the
+// names of sounds to the paths of their samples within the pack:
+//
+//	{"ufo": "saucer.wav", "shot": "sfx/fire.wav"}
+//
+// The sounds are ufo, shot, player-die, invader-die, fleet-1 to fleet-4,
+// ufo-hit and extended-play. Any sound not in the manifest is matched to the
+// sample with its MAME name, 0.wav to 9.wav, ignoring any directory and case.
+// Samples of any sample rate are resampled to SampleRate. Any sound missing
+// from the pack falls back to the embedded sample, except extended-play, which
+// has no embedded sample and is synthesised by the Latch.
+//
+// If path is empty only the embedded samples are returned.
+func LoadSamples(path string) (map[string][]float64, error) {
+	smps, err := Samples()
+	if err != nil || path == "" {
+		return smps, err
+	}
+
+	p, err := openSamplePack(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open sample pack (%q): %w", path, err)
+	}
+	defer p.close() //nolint:errcheck
+
+	files, err := p.samples()
+	if err != nil {
+		return nil, fmt.Errorf("sample pack (%q): %w", path, err)
+	}
+
+	for name, f := range files {
+		b, err := p.read(f)
+		if err != nil {
+			return nil, fmt.Errorf("could not read sample (%q in %q): %w", f, path, err)
+		}
+
+		s, rate, err := decodeWAV(b)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode sample (%q in %q): %w", f, path, err)
+		}
+		smps[name] = resample(s, rate, SampleRate)
+	}
+
+	return smps, nil
+}
+
+// openSamplePack opens the directory or zip archive at path.
+func openSamplePack(path string) (*samplePack, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fi.IsDir() {
+		return openSampleZip(path)
+	}
+
+	p := &samplePack{
+		read: func(name string) ([]byte, error) {
+			return ioutil.ReadFile(filepath.Join(path, filepath.FromSlash(name)))
+		},
+		close: func() error { return nil },
+	}
+	err = filepath.Walk(path, func(f string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(path, f)
+		if err != nil {
+			return err
+		}
+		p.files = append(p.files, filepath.ToSlash(rel))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(p.files)
+
+	return p, nil
+}
+
+// openSampleZip opens the zip archive at path.
+func openSampleZip(path string) (*samplePack, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	zfs := make(map[string]*zip.File, len(zr.File))
+	p := &samplePack{
+		read: func(name string) ([]byte, error) {
+			rc, err := zfs[name].Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+
+			return ioutil.ReadAll(rc)
+		},
+		close: zr.Close,
+	}
+	for _, zf := range zr.File {
+		if strings.HasSuffix(zf.Name, "/") {
+			continue
+		}
+		zfs[zf.Name] = zf
+		p.files = append(p.files, zf.Name)
+	}
+	sort.Strings(p.files)
+
+	return p, nil
+}
+
+// samples returns the file in the pack holding the sample of every sound it
+// supplies, keyed by sample name.
+func (p *samplePack) samples() (map[string]string, error) {
+	files := make(map[string]string)
+
+	// Sounds in the manifest are mapped to the files it names.
+	if p.contains(manifestName) {
+		b, err := p.read(manifestName)
+		if err != nil {
+			return nil, err
+		}
+		var mf map[string]string
+		if err = json.Unmarshal(b, &mf); err != nil {
+			return nil, fmt.Errorf("invalid manifest: %w", err)
+		}
+
+		for snd, mfile := range mf {
+			c, err := ParseChannel(snd)
+			if err != nil {
+				return nil, fmt.Errorf("manifest: %w", err)
+			}
+			if !p.contains(mfile) {
+				return nil, fmt.Errorf("manifest: %s: no such sample %q", snd, mfile)
+			}
+			files[channels[c].sample] = mfile
+		}
+	}
+
+	// Other sounds are mapped by their MAME name.
+	for _, c := range channels {
+		if _, ok := files[c.sample]; ok {
+			continue
+		}
+		for _, f := range p.files {
+			if strings.EqualFold(path.Base(f), c.sample) {
+				files[c.sample] = f
+				break
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// contains returns true if the pack contains the named file.
+func (p *samplePack) contains(name string) bool {
+	i := sort.SearchStrings(p.files, name)
+	return i < len(p.files) && p.files[i] == name
+}
@@ -0,0 +1,141 @@
+package sound
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writePackDir writes the given files, keyed by slash separated path, to a new
+// directory and returns its path.
+func writePackDir(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for name, b := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(p, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// writePackZip writes the given files, keyed by slash separated path, to a new
+// zip archive and returns its path.
+func writePackZip(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+
+	f, err := ioutil.TempFile("", "pack*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(f.Name()) })
+
+	zw := zip.NewWriter(f)
+	for name, b := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return f.Name()
+}
+
+// packWAV returns a short 8-bit WAV file at SampleRate, at the given level.
+func packWAV(level byte) []byte {
+	return makeWAV(wavPCM, 1, SampleRate, 8, []byte{level, level})
+}
+
+func TestLoadSamples(t *testing.T) {
+	embedded, err := Samples()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		// Matched by MAME name, ignoring the directory and case.
+		"invaders/0.WAV": packWAV(0xc0),
+		// Named by the manifest, which takes precedence over the MAME name.
+		"sfx/fire.wav": packWAV(0x40),
+		"1.wav":        packWAV(0xff),
+		// A sample at a different rate, which is resampled.
+		"3.wav":         makeWAV(wavPCM, 1, 2*SampleRate, 8, []byte{0xc0, 0xc0, 0xc0, 0xc0}),
+		"manifest.json": []byte(`{"shot": "sfx/fire.wav"}`),
+	}
+	want := map[string][]float64{
+		"0.wav": {0.5, 0.5},
+		"1.wav": {-0.5, -0.5},
+		"3.wav": {0.5, 0.5},
+	}
+
+	packs := map[string]string{
+		"directory": writePackDir(t, files),
+		"zip":       writePackZip(t, files),
+	}
+	for name, path := range packs {
+		t.Run(name, func(t *testing.T) {
+			smps, err := LoadSamples(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, w := range wavs {
+				exp, ok := want[w]
+				if !ok {
+					// Sounds missing from the pack fall back to the
+					// embedded samples.
+					exp = embedded[w]
+				}
+				if !reflect.DeepEqual(smps[w], exp) {
+					t.Errorf("%s: got %d samples, not the expected %d", w, len(smps[w]), len(exp))
+				}
+			}
+		})
+	}
+}
+
+func TestLoadSamplesInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+	}{
+		{"bad manifest", map[string][]byte{"manifest.json": []byte(`{"shot":`)}},
+		{"unknown sound", map[string][]byte{"manifest.json": []byte(`{"laser": "1.wav"}`), "1.wav": packWAV(0)}},
+		{"missing sample", map[string][]byte{"manifest.json": []byte(`{"shot": "fire.wav"}`)}},
+		{"bad sample", map[string][]byte{"2.wav": []byte("not a wav")}},
+	}
+	for _, tt := range tests {
+		for _, path := range []string{writePackDir(t, tt.files), writePackZip(t, tt.files)} {
+			if _, err := LoadSamples(path); err == nil {
+				t.Errorf("%s (%s): expected an error", tt.name, filepath.Ext(path))
+			}
+		}
+	}
+
+	if _, err := LoadSamples(filepath.Join(os.TempDir(), "no-such-pack")); err == nil {
+		t.Error("missing pack: expected an error")
+	}
+}
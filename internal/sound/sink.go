@@ -0,0 +1,179 @@
+package sound
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+
+	"github.com/danmrichards/go-invaders/internal/machine"
+)
+
+type (
+	// Null is an audio sink that discards all sound.
+	Null struct{}
+
+	// WAV is an audio sink that renders the output of a mixer to a 16-bit
+	// mono WAV file, at the output sample rate of the mixer, on the emulated
+	// timeline.
+	//
+	// Each value written to a sound port is latched at the sample it was
+	// written on, and every emulated frame adds the same length of sound,
+	// however fast the emulation runs.
+	WAV struct {
+		f *os.File
+		w *bufio.Writer
+
+		mx  *Mixer
+		buf []float64
+		pcm []int16
+
+		// The number of frames and samples written, and the first error
+		// writing them.
+		frames int64
+		pos    int64
+		err    error
+	}
+
+	// wavHeader is the header of a 16-bit mono PCM WAV file.
+	wavHeader struct {
+		RIFF          [4]byte
+		RIFFSize      uint32
+		WAVE          [4]byte
+		Fmt           [4]byte
+		FmtSize       uint32
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Data          [4]byte
+		DataSize      uint32
+	}
+)
+
+// Out implements machine.Audio.
+func (Null) Out(byte, byte, int) {}
+
+// Frame implements machine.Audio.
+func (Null) Frame() error { return nil }
+
+// NewWAV returns an audio sink that renders the output of the given mixer to a
+// WAV file at path.
+func NewWAV(path string, mx *Mixer) (*WAV, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WAV{
+		f:  f,
+		w:  bufio.NewWriter(f),
+		mx: mx,
+	}
+
+	// The sizes are filled in once the file is closed.
+	if err = binary.Write(w.w, binary.LittleEndian, w.header()); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Out implements machine.Audio.
+func (w *WAV) Out(port, data byte, cycle int) {
+	if w.err != nil {
+		return
+	}
+
+	at := FrameSamples(w.frames, w.mx.Rate()) + int64(cycle)*int64(w.mx.Rate())/machine.ClockSpeed
+	w.err = w.mix(at)
+	w.mx.Out(port, data)
+}
+
+// Frame implements machine.Audio. It writes the remaining samples of the
+// current frame, and returns the first error writing the file.
+func (w *WAV) Frame() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.frames++
+	w.err = w.mix(FrameSamples(w.frames, w.mx.Rate()))
+
+	return w.err
+}
+
+// Close fills in the header and closes the WAV file.
+func (w *WAV) Close() error {
+	if err := w.w.Flush(); err != nil {
+		w.f.Close()
+		return err
+	}
+
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		w.f.Close()
+		return err
+	}
+	if err := binary.Write(w.f, binary.LittleEndian, w.header()); err != nil {
+		w.f.Close()
+		return err
+	}
+
+	return w.f.Close()
+}
+
+// mix writes the mixed samples up to the given sample.
+func (w *WAV) mix(end int64) error {
+	if end <= w.pos {
+		return nil
+	}
+
+	n := int(end - w.pos)
+	if len(w.buf) < n {
+		w.buf = make([]float64, n)
+		w.pcm = make([]int16, n)
+	}
+	w.mx.Mix(w.buf[:n])
+
+	for i, v := range w.buf[:n] {
+		w.pcm[i] = int16(v * math.MaxInt16)
+	}
+	if err := binary.Write(w.w, binary.LittleEndian, w.pcm[:n]); err != nil {
+		return err
+	}
+	w.pos = end
+
+	return nil
+}
+
+// header returns the WAV header for the samples written so far.
+func (w *WAV) header() wavHeader {
+	size := uint32(w.pos * 2)
+
+	return wavHeader{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		RIFFSize:      36 + size,
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		Fmt:           [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		Format:        1,
+		Channels:      1,
+		SampleRate:    uint32(w.mx.Rate()),
+		ByteRate:      uint32(w.mx.Rate() * 2),
+		BlockAlign:    2,
+		BitsPerSample: 16,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      size,
+	}
+}
+
+// FrameSamples returns the number of samples at the given sample rate in the
+// given number of emulated frames, rounded to the nearest sample. Audio sinks
+// use it to place the sound they mix on the emulated timeline.
+func FrameSamples(frames int64, rate int) int64 {
+	return (frames*int64(machine.CyclesPerFrame)*int64(rate) + machine.ClockSpeed/2) / machine.ClockSpeed
+}
@@ -0,0 +1,87 @@
+package sound
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danmrichards/go-invaders/internal/machine"
+)
+
+func TestWAV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The shot channel alone, at half volume, plays a constant level.
+	src := &testSource{}
+	src.smp[ChannelShot] = 1
+	s := DefaultMixerSettings()
+	s.Volume = 0.5
+	mx, err := NewMixer(src, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "out.wav")
+	w, err := NewWAV(path, mx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const frames = 10
+	for i := 0; i < frames; i++ {
+		// A write part way through a frame must not add to its length.
+		w.Out(3, 0, machine.CyclesPerFrame/2)
+		if err = w.Frame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := FrameSamples(frames, mx.Rate())
+	var h wavHeader
+	if err = binary.Read(bytes.NewReader(b), binary.LittleEndian, &h); err != nil {
+		t.Fatal(err)
+	}
+	if want := uint32(n * 2); h.DataSize != want {
+		t.Errorf("data size = %d, want %d", h.DataSize, want)
+	}
+	if want := uint32(36 + n*2); h.RIFFSize != want {
+		t.Errorf("RIFF size = %d, want %d", h.RIFFSize, want)
+	}
+	if want := 44 + int(n*2); len(b) != want {
+		t.Errorf("file is %d bytes, want %d", len(b), want)
+	}
+
+	smps, rate, err := decodeWAV(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != mx.Rate() {
+		t.Errorf("rate = %d, want %d", rate, mx.Rate())
+	}
+	if int64(len(smps)) != n {
+		t.Fatalf("decoded %d samples, want %d", len(smps), n)
+	}
+
+	// Skip the first frame, while the interpolation buffer fills.
+	skip := FrameSamples(1, mx.Rate())
+	for i, v := range smps[skip:] {
+		if v < 0.49 || v > 0.51 {
+			t.Fatalf("sample %d = %g, want 0.5", skip+int64(i), v)
+		}
+	}
+}
@@ -1,18 +1,21 @@
+// Package sound implements the sound engines that model the sound board of the
+// machine, and the audio sinks that record or discard their output. The sink
+// that plays their output through the speaker is in package speaker, as it
+// needs cgo.
 package sound
 
 import (
-	"bytes"
-	"io/ioutil"
-	"time"
+	"fmt"
 
 	"github.com/faiface/beep"
-	"github.com/faiface/beep/speaker"
-	"github.com/faiface/beep/wav"
 	"github.com/gobuffalo/packr/v2"
 )
 
 const sr = beep.SampleRate(11025)
 
+// SampleRate is the sample rate of the sounds, in Hz.
+const SampleRate = int(sr)
+
 var (
 	box  = packr.New("sound", "./data")
 	wavs = []string{
@@ -28,74 +31,84 @@ var (
 	}
 )
 
-func init() {
-	speaker.Init(sr, sr.N(time.Second/10)) //nolint:errcheck
-}
-
-type sound struct {
-	fmt   beep.Format
-	strmr beep.StreamCloser
-}
+type (
+	// Source is the interface that sound engines are expected to implement.
+	//
+	// Out latches a value written to sound port 3 or 5.
+	//
+	// Mix fills out with the next samples of every sound channel, at
+	// SampleRate.
+	//
+	// Sources are not safe for concurrent use. The sink that drives a source
+	// serialises the calls to it.
+	Source interface {
+		Out(port, data byte)
+		Mix(out []Sample)
+	}
 
-// Player is responsible for playing buffered sounds.
-type Player struct {
-	bufs []*beep.Buffer
-}
+	// Player plays the recorded samples of the sound channels, as driven by
+	// the sound latches.
+	Player struct {
+		l *Latch
 
-// NewPlayer returns an instantiated sound player with all sounds buffered.
-func NewPlayer() (*Player, error) {
-	snds, err := prepareSounds()
-	if err != nil {
-		return nil, err
+		// The path of the sample pack, empty for the embedded samples.
+		pack string
 	}
 
-	p := &Player{
-		bufs: make([]*beep.Buffer, 0, len(wavs)),
+	// Option is a functional option that modifies a field on the player.
+	Option func(*Player)
+)
+
+// WithSamplePack sets the path of a directory or zip archive of samples that
+// replace the embedded samples, as loaded by LoadSamples.
+func WithSamplePack(path string) Option {
+	return func(p *Player) {
+		p.pack = path
 	}
+}
 
-	for _, s := range snds {
-		b := beep.NewBuffer(s.fmt)
-		b.Append(s.strmr)
-		s.strmr.Close()
+// NewPlayer returns an instantiated sound player, with all sounds buffered.
+func NewPlayer(opts ...Option) (*Player, error) {
+	p := &Player{}
+	for _, o := range opts {
+		o(p)
+	}
 
-		p.bufs = append(p.bufs, b)
+	smps, err := LoadSamples(p.pack)
+	if err != nil {
+		return nil, err
 	}
+	p.l = NewLatch(smps)
 
 	return p, nil
 }
 
-// Play plays the given sound.
-func (p *Player) Play(name string) {
-	var i int
-	for wi, wn := range wavs {
-		if wn == name {
-			i = wi
-		}
-	}
-
-	speaker.Play(p.bufs[i].Streamer(i, p.bufs[i].Len()))
+// Out implements Source.
+func (p *Player) Out(port, data byte) {
+	p.l.Out(port, data)
 }
 
-// prepareSounds returns a list of decoded wave files.
-func prepareSounds() ([]sound, error) {
-	snds := make([]sound, 0, len(wavs))
+// Mix implements Source.
+func (p *Player) Mix(out []Sample) {
+	p.l.Mix(out)
+}
 
+// Samples returns the decoded mono samples of every embedded sound, from -1 to
+// 1, keyed by name.
+func Samples() (map[string][]float64, error) {
+	smps := make(map[string][]float64, len(wavs))
 	for _, w := range wavs {
 		b, err := box.Find(w)
 		if err != nil {
 			return nil, err
 		}
 
-		ws, wf, err := wav.Decode(ioutil.NopCloser(bytes.NewReader(b)))
+		s, rate, err := decodeWAV(b)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("could not decode sound (%q): %w", w, err)
 		}
-
-		snds = append(snds, sound{
-			fmt:   wf,
-			strmr: ws,
-		})
+		smps[w] = resample(s, rate, SampleRate)
 	}
 
-	return snds, nil
+	return smps, nil
 }
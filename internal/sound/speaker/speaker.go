@@ -0,0 +1,241 @@
+// Package speaker implements an audio sink that plays sound through the
+// speaker.
+//
+// It is kept apart from package sound as it needs cgo, and the audio libraries
+// of the host, to build.
+package speaker
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/danmrichards/go-invaders/internal/machine"
+	"github.com/danmrichards/go-invaders/internal/sound"
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+const (
+	// The length of sound the speaker pulls from the sink at a time.
+	speakerChunk = time.Second / 50
+
+	// The length of sound the sink aims to keep waiting to be played, and
+	// the length above which the oldest sound is dropped, such as while
+	// emulating faster than normal speed.
+	bufferTarget = 60 * time.Millisecond
+	bufferMax    = 4 * bufferTarget
+
+	// The most the output rate is adjusted by to keep the sound waiting to
+	// be played at its target length. A change in pitch of 0.5% is too small
+	// to hear.
+	maxRateAdjust = 0.005
+
+	// The number of frames the buffer level is averaged over.
+	levelFrames = 30
+
+	// The shortest time Wait returns, so that callers do not spin.
+	minWait = time.Millisecond
+)
+
+var (
+	// The speaker is initialised by the first speaker sink, at the output
+	// sample rate of its mixer, and shared by every speaker sink after it.
+	speakerOnce sync.Once
+	speakerRate int
+	speakerErr  error
+)
+
+// Speaker is an audio sink that plays the output of a mixer through the
+// speaker in real time.
+//
+// The sound is mixed on the emulated timeline as each frame is emulated, with
+// each value written to a sound port latched at the sample it was written on,
+// and buffered until it is played. The output rate is adjusted very slightly,
+// by dynamic rate control, to keep the buffer at its target length, so that
+// the difference between the emulated and audio clocks neither drains the
+// buffer, causing crackles, nor lets the sound drift behind.
+//
+// Speaker also implements machine.Clock, to pace emulation by the sound
+// played rather than by wall time.
+type Speaker struct {
+	mx *sound.Mixer
+
+	// The number of samples in a frame at the output sample rate, and the
+	// target and most number of samples waiting to be played.
+	frame  int64
+	target int
+	max    int
+
+	// The mixed samples waiting to be played, and whether the sink is
+	// closed, removing it from the speaker.
+	mu     sync.Mutex
+	buf    []float64
+	closed bool
+
+	// The number of frames emulated, the position of the end of the mixed
+	// sound on the emulated timeline, in samples at the output sample rate,
+	// and the fraction of a sample carried over from the last mix.
+	frames int64
+	pos    int64
+	carry  float64
+
+	// The number of samples waiting to be played, averaged over recent
+	// frames, the ratio of the number of samples mixed to the number on the
+	// emulated timeline, and scratch space to mix them in.
+	level float64
+	ratio float64
+	mix   []float64
+}
+
+// New returns an audio sink that plays the output of the given mixer through
+// the speaker. The audio device is opened by the first call, at the output
+// sample rate of the mixer.
+func New(mx *sound.Mixer) (*Speaker, error) {
+	rate := beep.SampleRate(mx.Rate())
+	speakerOnce.Do(func() {
+		speakerRate, speakerErr = mx.Rate(), speaker.Init(rate, rate.N(speakerChunk))
+	})
+	if speakerErr != nil {
+		return nil, fmt.Errorf("could not open audio device: %w", speakerErr)
+	}
+	if mx.Rate() != speakerRate {
+		return nil, fmt.Errorf("audio device is open at %d Hz, not %d Hz", speakerRate, mx.Rate())
+	}
+
+	s := &Speaker{
+		mx:     mx,
+		frame:  sound.FrameSamples(1, mx.Rate()),
+		target: rate.N(bufferTarget),
+		max:    rate.N(bufferMax),
+		ratio:  1,
+	}
+	speaker.Play(s)
+
+	return s, nil
+}
+
+// Out implements machine.Audio.
+func (s *Speaker) Out(port, data byte, cycle int) {
+	s.mixTo(sound.FrameSamples(s.frames, s.mx.Rate()) + int64(cycle)*int64(s.mx.Rate())/machine.ClockSpeed)
+	s.mx.Out(port, data)
+}
+
+// Frame implements machine.Audio. It mixes the remaining samples of the
+// current frame, and adjusts the output rate by the number of samples waiting
+// to be played.
+func (s *Speaker) Frame() error {
+	s.frames++
+	s.mixTo(sound.FrameSamples(s.frames, s.mx.Rate()))
+
+	s.mu.Lock()
+	if len(s.buf) > s.max {
+		s.buf = s.buf[:copy(s.buf, s.buf[len(s.buf)-s.target:])]
+	}
+	n := len(s.buf)
+	s.mu.Unlock()
+
+	// Mix more samples while the buffer is short of its target, and fewer
+	// while it is over. The level is averaged, as it rises a frame at a time
+	// and falls as the speaker pulls each chunk.
+	s.level += (float64(n) - s.level) / levelFrames
+	d := (float64(s.target) - s.level) / float64(s.target)
+	s.ratio = 1 + maxRateAdjust*math.Max(-1, math.Min(1, d))
+	s.mx.SetRatio(s.ratio)
+
+	return nil
+}
+
+// Due implements machine.Clock. Frames are due while fewer samples are
+// waiting to be played than the target.
+func (s *Speaker) Due() int {
+	short := int64(s.target - s.buffered())
+	if short <= 0 {
+		return 0
+	}
+
+	return int((short + s.frame - 1) / s.frame)
+}
+
+// Wait implements machine.Clock. It returns how long until fewer samples are
+// waiting to be played than the target.
+func (s *Speaker) Wait() time.Duration {
+	d := time.Duration(s.buffered()-s.target) * time.Second / time.Duration(s.mx.Rate())
+	if d < minWait {
+		return minWait
+	}
+
+	return d
+}
+
+// Close stops playing through the speaker.
+func (s *Speaker) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Stream implements beep.Streamer. Silence is played if the buffer runs dry,
+// such as while emulation is paused.
+func (s *Speaker) Stream(samples [][2]float64) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, false
+	}
+
+	for i := range samples {
+		var v float64
+		if i < len(s.buf) {
+			v = s.buf[i]
+		}
+		samples[i] = [2]float64{v, v}
+	}
+
+	n := len(samples)
+	if n > len(s.buf) {
+		n = len(s.buf)
+	}
+	s.buf = s.buf[:copy(s.buf, s.buf[n:])]
+
+	return len(samples), true
+}
+
+// Err implements beep.Streamer.
+func (s *Speaker) Err() error {
+	return nil
+}
+
+// mixTo mixes the sound up to the given sample on the emulated timeline into
+// the buffer.
+func (s *Speaker) mixTo(end int64) {
+	if end <= s.pos {
+		return
+	}
+
+	s.carry += float64(end-s.pos) * s.ratio
+	n := int(s.carry)
+	s.carry -= float64(n)
+	s.pos = end
+
+	if len(s.mix) < n {
+		s.mix = make([]float64, n)
+	}
+	s.mx.Mix(s.mix[:n])
+
+	s.mu.Lock()
+	s.buf = append(s.buf, s.mix[:n]...)
+	s.mu.Unlock()
+}
+
+// buffered returns the number of samples waiting to be played.
+func (s *Speaker) buffered() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.buf)
+}
@@ -0,0 +1,244 @@
+package sound
+
+import (
+	"math"
+)
+
+type (
+	// Synth synthesises the sound of the analog sound board of the original
+	// machine, rather than playing recorded samples.
+	//
+	// Each bit written to sound ports 3 and 5 drives one sound circuit. The
+	// UFO and extended play sounds are heard for as long as their bit is set,
+	// and the others are triggered by their bit being set. As with the
+	// samples, all output is muted while the amplifier is disabled.
+	Synth struct {
+		// The sample rate, in Hz.
+		rate float64
+
+		// The sound circuits, in the order of the channels: driven by the
+		// bits of port 3 then port 5.
+		cs [NumChannels]*circuit
+
+		// The state of the noise generator.
+		noise uint32
+
+		// Whether the amplifier is enabled.
+		amp bool
+	}
+
+	// circuit is a single sound circuit of the sound board.
+	circuit struct {
+		// The function that generates the sound, t seconds after it was
+		// triggered. It returns false once the sound has finished.
+		gen func(s *Synth, c *circuit, t float64) (float64, bool)
+
+		// True if the circuit plays for as long as its bit is set, rather
+		// than once each time its bit is set.
+		gated bool
+
+		// The state of the driving bit, whether the sound is playing and the
+		// number of samples since it was triggered.
+		bit     bool
+		playing bool
+		n       int
+
+		// The phase of the oscillator, from 0 to 1, and the state of the
+		// low pass filter.
+		phase float64
+		lp    float64
+	}
+)
+
+// The frequencies of the four notes of the fleet movement, in Hz, which
+// descend as the invaders march.
+var fleetNotes = [4]float64{61.8, 55.1, 49.1, 43.7}
+
+// NewSynth returns an instantiated synthesiser.
+func NewSynth() *Synth {
+	s := &Synth{
+		rate:  float64(sr),
+		noise: 0x1ffff,
+		cs: [NumChannels]*circuit{
+			ChannelUFO:          {gen: ufo, gated: true},
+			ChannelShot:         {gen: shot},
+			ChannelPlayerDie:    {gen: playerDie},
+			ChannelInvaderDie:   {gen: invaderDie},
+			ChannelExtendedPlay: {gen: extendedPlay, gated: true},
+			ChannelFleet1:       {gen: fleet(0)},
+			ChannelFleet2:       {gen: fleet(1)},
+			ChannelFleet3:       {gen: fleet(2)},
+			ChannelFleet4:       {gen: fleet(3)},
+			ChannelUFOHit:       {gen: ufoHit},
+		},
+	}
+
+	return s
+}
+
+// Out implements Source.
+func (s *Synth) Out(port, data byte) {
+	var cs []*circuit
+	switch port {
+	case 0x03:
+		cs = s.cs[ChannelUFO:ChannelFleet1]
+	case 0x05:
+		cs = s.cs[ChannelFleet1:]
+	default:
+		return
+	}
+
+	if port == 0x03 {
+		s.amp = data&ampEnable != 0
+	}
+
+	for i, c := range cs {
+		bit := data&(0x01<<i) != 0
+		switch {
+		case bit && !c.bit:
+			// Rising edge: trigger the sound from the start.
+			c.playing, c.n = true, 0
+		case !bit && c.gated:
+			c.playing = false
+		}
+		c.bit = bit
+	}
+}
+
+// Mix implements Source.
+func (s *Synth) Mix(out []Sample) {
+	for i := range out {
+		out[i] = Sample{}
+		for ch, c := range &s.cs {
+			if !c.playing {
+				continue
+			}
+
+			cv := c.next(s)
+			if s.amp {
+				out[i][ch] = cv
+			}
+		}
+	}
+}
+
+// next returns the next sample of a playing circuit.
+func (c *circuit) next(s *Synth) float64 {
+	cv, ok := c.gen(s, c, float64(c.n)/s.rate)
+	c.playing = ok
+	c.n++
+
+	return cv
+}
+
+// white returns the next value of the noise generator, from -1 to 1. Like the
+// sound board, it uses a 17-bit linear feedback shift register.
+func (s *Synth) white() float64 {
+	bit := (s.noise ^ s.noise>>3) & 0x01
+	s.noise = s.noise>>1 | bit<<16
+
+	if s.noise&0x01 != 0 {
+		return 1
+	}
+	return -1
+}
+
+// osc advances the oscillator of the circuit at the given frequency and
+// returns its phase, from 0 to 1.
+func (c *circuit) osc(s *Synth, freq float64) float64 {
+	c.phase += freq / s.rate
+	c.phase -= math.Floor(c.phase)
+
+	return c.phase
+}
+
+// lowPass filters v with a one pole low pass filter of the given cut off
+// frequency.
+func (c *circuit) lowPass(s *Synth, v, cutoff float64) float64 {
+	a := 1 - math.Exp(-2*math.Pi*cutoff/s.rate)
+	c.lp += a * (v - c.lp)
+
+	return c.lp
+}
+
+// square returns a square wave of the given phase.
+func square(phase float64) float64 {
+	if phase < 0.5 {
+		return 1
+	}
+	return -1
+}
+
+// triangle returns a triangle wave of the given phase.
+func triangle(phase float64) float64 {
+	return 1 - 4*math.Abs(phase-0.5)
+}
+
+// ufo is the siren of the flying saucer: a square wave swept up and down by a
+// slow triangle wave.
+func ufo(s *Synth, c *circuit, t float64) (float64, bool) {
+	lfo := triangle(math.Mod(t*6, 1))
+	freq := 850 + 300*lfo
+
+	return 0.15 * c.lowPass(s, square(c.osc(s, freq)), 2500), true
+}
+
+// shot is the player's shot: a burst of filtered noise with a falling pitch.
+func shot(s *Synth, c *circuit, t float64) (float64, bool) {
+	env := math.Exp(-t / 0.12)
+	v := c.lowPass(s, s.white(), 600+3000*env)
+
+	return 0.45 * env * v, t < 0.6
+}
+
+// playerDie is the explosion of the player's base: a long burst of low
+// rumbling noise.
+func playerDie(s *Synth, c *circuit, t float64) (float64, bool) {
+	env := math.Exp(-t / 0.45)
+	v := c.lowPass(s, s.white(), 500)
+
+	return 0.9 * env * v, t < 2
+}
+
+// invaderDie is the explosion of an invader: a short burst of noise mixed
+// with a falling tone.
+func invaderDie(s *Synth, c *circuit, t float64) (float64, bool) {
+	env := math.Exp(-t / 0.07)
+	tone := triangle(c.osc(s, 450-250*t/0.35))
+
+	return 0.4 * env * (0.5*s.white() + 0.5*tone), t < 0.35
+}
+
+// extendedPlay is the chime of the extra base awarded by the bonus life: a
+// high tone that beeps for as long as its bit is set.
+func extendedPlay(s *Synth, c *circuit, t float64) (float64, bool) {
+	gate := 0.0
+	if math.Mod(t*8, 1) < 0.5 {
+		gate = 1
+	}
+
+	return 0.2 * gate * square(c.osc(s, 1200)), true
+}
+
+// fleet returns the circuit generator of the given note of the fleet
+// movement: a short, low thump.
+func fleet(note int) func(s *Synth, c *circuit, t float64) (float64, bool) {
+	return func(s *Synth, c *circuit, t float64) (float64, bool) {
+		env := math.Exp(-t / 0.05)
+		v := c.lowPass(s, square(c.osc(s, fleetNotes[note])), 400)
+
+		return 0.8 * env * v, t < 0.2
+	}
+}
+
+// ufoHit is the explosion of the flying saucer: a warbling tone that slowly
+// dies away.
+func ufoHit(s *Synth, c *circuit, t float64) (float64, bool) {
+	const length = 2.2
+
+	lfo := math.Sin(2 * math.Pi * 9 * t)
+	freq := 700 + 250*lfo - 200*t/length
+	env := 1 - t/length
+
+	return 0.3 * env * c.lowPass(s, square(c.osc(s, freq)), 2000), t < length
+}
@@ -0,0 +1,101 @@
+package sound
+
+import "testing"
+
+func TestSynthMixAllocs(t *testing.T) {
+	s := NewSynth()
+	s.Out(0x03, ampEnable|0x1f)
+	s.Out(0x05, 0x1f)
+
+	out := make([]Sample, 1)
+	if n := testing.AllocsPerRun(100, func() { s.Mix(out) }); n != 0 {
+		t.Errorf("Mix allocates %g times per call, want 0", n)
+	}
+}
+
+func TestEnginesGated(t *testing.T) {
+	smps := make(map[string][]float64)
+	for _, c := range channels {
+		smps[c.sample] = make([]float64, SampleRate)
+		for i := range smps[c.sample] {
+			smps[c.sample][i] = 0.5
+		}
+	}
+
+	engines := []struct {
+		name string
+		src  Source
+	}{
+		{"latch", NewLatch(smps)},
+		{"synth", NewSynth()},
+	}
+	for _, e := range engines {
+		t.Run(e.name, func(t *testing.T) {
+			// playing reports whether any of the next samples of the channel
+			// are not silent.
+			playing := func(ch Channel) bool {
+				out := make([]Sample, SampleRate/10)
+				e.src.Mix(out)
+				for _, s := range out {
+					if s[ch] != 0 {
+						return true
+					}
+				}
+				return false
+			}
+
+			// Every channel is heard once its bit is set, and the gated
+			// channels are silenced as soon as their bit is cleared.
+			for ch, c := range channels {
+				// Keep the amplifier enabled while writing port 3.
+				var amp byte
+				if c.port == 0x03 {
+					amp = ampEnable
+				}
+
+				e.src.Out(0x03, ampEnable)
+				e.src.Out(c.port, amp|0x01<<c.bit)
+				if !playing(Channel(ch)) {
+					t.Errorf("%s: silent with its bit set", Channel(ch))
+				}
+
+				e.src.Out(c.port, amp)
+				if got := playing(Channel(ch)); got == c.gated {
+					t.Errorf("%s: playing = %t after its bit is cleared, want %t", Channel(ch), got, !c.gated)
+				}
+			}
+		})
+	}
+}
+
+func TestLatchSynthFallback(t *testing.T) {
+	smps, err := Samples()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := smps[channels[ChannelExtendedPlay].sample]; ok {
+		t.Fatal("expected no embedded extended play sample")
+	}
+
+	// The extended play chime, which has no sample, sounds as it does from the
+	// synthesiser.
+	l, s := NewLatch(smps), NewSynth()
+	for _, src := range []Source{l, s} {
+		src.Out(0x03, ampEnable|0x01<<channels[ChannelExtendedPlay].bit)
+	}
+
+	got, want := make([]Sample, SampleRate/10), make([]Sample, SampleRate/10)
+	l.Mix(got)
+	s.Mix(want)
+
+	var heard bool
+	for i := range got {
+		if got[i][ChannelExtendedPlay] != want[i][ChannelExtendedPlay] {
+			t.Fatalf("sample %d = %g, want %g", i, got[i][ChannelExtendedPlay], want[i][ChannelExtendedPlay])
+		}
+		heard = heard || got[i][ChannelExtendedPlay] != 0
+	}
+	if !heard {
+		t.Error("extended play is silent")
+	}
+}
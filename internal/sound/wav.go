@@ -0,0 +1,176 @@
+package sound
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// WAV format tags.
+const (
+	wavPCM        = 0x0001
+	wavFloat      = 0x0003
+	wavExtensible = 0xfffe
+)
+
+// wavFormat is the format of the samples in a WAV file.
+type wavFormat struct {
+	tag      uint16
+	channels int
+	rate     int
+	bits     int
+}
+
+// decodeWAV returns the samples of the given WAV file mixed down to mono, from
+// -1 to 1, and their sample rate.
+//
+// Integer PCM samples of 8, 16, 24 or 32 bits and floating point samples of 32
+// or 64 bits are supported, with any number of channels.
+func decodeWAV(b []byte) ([]float64, int, error) {
+	if len(b) < 12 || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, 0, errors.New("not a WAV file")
+	}
+
+	var (
+		wf   *wavFormat
+		data []byte
+	)
+	for b = b[12:]; len(b) >= 8 && (wf == nil || data == nil); {
+		id, size := string(b[0:4]), int(binary.LittleEndian.Uint32(b[4:8]))
+		b = b[8:]
+		if size > len(b) {
+			// Tolerate a truncated final chunk, as written by some tools
+			// that never patch the header.
+			size = len(b)
+		}
+
+		switch id {
+		case "fmt ":
+			var err error
+			if wf, err = parseWAVFormat(b[:size]); err != nil {
+				return nil, 0, err
+			}
+		case "data":
+			data = b[:size]
+		}
+
+		// Chunks are padded to an even size.
+		if size%2 == 1 && size < len(b) {
+			size++
+		}
+		b = b[size:]
+	}
+	if wf == nil {
+		return nil, 0, errors.New("missing format chunk")
+	}
+	if data == nil {
+		return nil, 0, errors.New("missing data chunk")
+	}
+
+	width := wf.bits / 8
+	frame := width * wf.channels
+	smps := make([]float64, len(data)/frame)
+	for i := range smps {
+		var v float64
+		for c := 0; c < wf.channels; c++ {
+			v += wavSample(data[i*frame+c*width:], wf)
+		}
+		smps[i] = v / float64(wf.channels)
+	}
+
+	return smps, wf.rate, nil
+}
+
+// parseWAVFormat returns the sample format described by the given format chunk.
+func parseWAVFormat(b []byte) (*wavFormat, error) {
+	if len(b) < 16 {
+		return nil, errors.New("short format chunk")
+	}
+
+	wf := &wavFormat{
+		tag:      binary.LittleEndian.Uint16(b[0:2]),
+		channels: int(binary.LittleEndian.Uint16(b[2:4])),
+		rate:     int(binary.LittleEndian.Uint32(b[4:8])),
+		bits:     int(binary.LittleEndian.Uint16(b[14:16])),
+	}
+	if wf.tag == wavExtensible {
+		// The real format tag is the first 2 bytes of the sub format GUID.
+		if len(b) < 26 {
+			return nil, errors.New("short extensible format chunk")
+		}
+		wf.tag = binary.LittleEndian.Uint16(b[24:26])
+	}
+
+	switch {
+	case wf.channels < 1:
+		return nil, errors.New("no channels")
+	case wf.rate < 1:
+		return nil, fmt.Errorf("invalid sample rate %d", wf.rate)
+	case wf.tag == wavPCM && (wf.bits == 8 || wf.bits == 16 || wf.bits == 24 || wf.bits == 32):
+	case wf.tag == wavFloat && (wf.bits == 32 || wf.bits == 64):
+	default:
+		return nil, fmt.Errorf("unsupported format %#04x with %d bits per sample", wf.tag, wf.bits)
+	}
+
+	return wf, nil
+}
+
+// wavSample returns the sample at the start of b, from -1 to 1.
+func wavSample(b []byte, wf *wavFormat) float64 {
+	switch {
+	case wf.tag == wavFloat && wf.bits == 32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case wf.tag == wavFloat:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	case wf.bits == 8:
+		// 8-bit samples are unsigned.
+		return (float64(b[0]) - 128) / 128
+	case wf.bits == 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case wf.bits == 24:
+		v := int32(b[0])<<8 | int32(b[1])<<16 | int32(b[2])<<24
+		return float64(v>>8) / (1 << 23)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+}
+
+// resample returns the given samples converted from one sample rate to
+// another.
+//
+// When upsampling, each output sample is linearly interpolated between the
+// nearest input samples. When downsampling, each output sample is the average
+// of the input samples it spans, to avoid aliasing.
+func resample(smps []float64, from, to int) []float64 {
+	if from == to || len(smps) == 0 {
+		return smps
+	}
+
+	step := float64(from) / float64(to)
+	out := make([]float64, int(float64(len(smps))/step))
+	for i := range out {
+		pos := float64(i) * step
+		if step > 1 {
+			lo, hi := int(pos), int(pos+step)
+			if hi > len(smps) {
+				hi = len(smps)
+			}
+			var v float64
+			for _, s := range smps[lo:hi] {
+				v += s
+			}
+			out[i] = v / float64(hi-lo)
+			continue
+		}
+
+		j, frac := int(pos), pos-math.Floor(pos)
+		next := smps[j]
+		if j+1 < len(smps) {
+			next = smps[j+1]
+		}
+		out[i] = smps[j] + (next-smps[j])*frac
+	}
+
+	return out
+}
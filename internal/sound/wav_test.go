@@ -0,0 +1,171 @@
+package sound
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// makeWAV returns a WAV file with the given format and sample data, and any
+// extra chunks placed between the format and data chunks.
+func makeWAV(tag uint16, channels, rate, bits int, data []byte, extra ...[]byte) []byte {
+	var fmtc bytes.Buffer
+	le := binary.LittleEndian
+	binary.Write(&fmtc, le, tag)                          //nolint:errcheck
+	binary.Write(&fmtc, le, uint16(channels))             //nolint:errcheck
+	binary.Write(&fmtc, le, uint32(rate))                 //nolint:errcheck
+	binary.Write(&fmtc, le, uint32(rate*channels*bits/8)) //nolint:errcheck
+	binary.Write(&fmtc, le, uint16(channels*bits/8))      //nolint:errcheck
+	binary.Write(&fmtc, le, uint16(bits))                 //nolint:errcheck
+
+	var body bytes.Buffer
+	body.WriteString("WAVE")
+	chunk := func(id string, b []byte) {
+		body.WriteString(id)
+		binary.Write(&body, le, uint32(len(b))) //nolint:errcheck
+		body.Write(b)
+		if len(b)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+	chunk("fmt ", fmtc.Bytes())
+	for _, e := range extra {
+		chunk("LIST", e)
+	}
+	chunk("data", data)
+
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, le, uint32(body.Len())) //nolint:errcheck
+	b.Write(body.Bytes())
+
+	return b.Bytes()
+}
+
+// extensible returns the WAV file b with its format chunk rewritten as
+// WAVE_FORMAT_EXTENSIBLE, with the original format tag as the sub format.
+func extensible(b []byte) []byte {
+	le := binary.LittleEndian
+	tag := le.Uint16(b[20:22])
+
+	ext := make([]byte, 40)
+	copy(ext, b[20:36])
+	le.PutUint16(ext[0:2], wavExtensible)
+	le.PutUint16(ext[16:18], 22)
+	le.PutUint16(ext[24:26], tag)
+
+	out := append([]byte(nil), b[:20]...)
+	le.PutUint32(out[16:20], uint32(len(ext)))
+	out = append(out, ext...)
+	out = append(out, b[36:]...)
+	le.PutUint32(out[4:8], uint32(len(out)-8))
+
+	return out
+}
+
+// leBytes returns the little endian bytes of the given values.
+func leBytes(vs ...interface{}) []byte {
+	var b bytes.Buffer
+	for _, v := range vs {
+		binary.Write(&b, binary.LittleEndian, v) //nolint:errcheck
+	}
+	return b.Bytes()
+}
+
+func TestDecodeWAV(t *testing.T) {
+	tests := []struct {
+		name string
+		wav  []byte
+		want []float64
+	}{
+		{"8 bit", makeWAV(wavPCM, 1, 11025, 8, []byte{0x00, 0x80, 0xc0}), []float64{-1, 0, 0.5}},
+		{"16 bit", makeWAV(wavPCM, 1, 11025, 16, leBytes(int16(-32768), int16(0), int16(16384))), []float64{-1, 0, 0.5}},
+		{"24 bit", makeWAV(wavPCM, 1, 11025, 24, []byte{0x00, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0}), []float64{-1, 0, -0.5}},
+		{"32 bit", makeWAV(wavPCM, 1, 11025, 32, leBytes(int32(math.MinInt32), int32(1<<30))), []float64{-1, 0.5}},
+		{"32 bit float", makeWAV(wavFloat, 1, 11025, 32, leBytes(float32(-0.25), float32(0.75))), []float64{-0.25, 0.75}},
+		{"64 bit float", makeWAV(wavFloat, 1, 11025, 64, leBytes(-0.125, 0.5)), []float64{-0.125, 0.5}},
+		{"stereo", makeWAV(wavPCM, 2, 11025, 16, leBytes(int16(16384), int16(-16384), int16(16384), int16(16384))), []float64{0, 0.5}},
+		{"extensible", extensible(makeWAV(wavPCM, 1, 11025, 16, leBytes(int16(16384)))), []float64{0.5}},
+		{"odd chunk", makeWAV(wavPCM, 1, 11025, 8, []byte{0xc0}, []byte{1, 2, 3}), []float64{0.5}},
+	}
+	for _, tt := range tests {
+		got, rate, err := decodeWAV(tt.wav)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if rate != 11025 {
+			t.Errorf("%s: rate = %d, want 11025", tt.name, rate)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: %d samples, want %d", tt.name, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+				t.Errorf("%s: sample %d = %g, want %g", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestDecodeWAVInvalid(t *testing.T) {
+	good := makeWAV(wavPCM, 1, 11025, 16, leBytes(int16(0)))
+
+	// noChunk returns good without the chunk with the given ID.
+	noChunk := func(id string) []byte {
+		i := bytes.Index(good, []byte(id))
+		size := int(binary.LittleEndian.Uint32(good[i+4:]))
+		return append(append([]byte(nil), good[:i]...), good[i+8+size:]...)
+	}
+
+	tests := []struct {
+		name string
+		wav  []byte
+	}{
+		{"empty", nil},
+		{"not RIFF", append([]byte("RIFX"), good[4:]...)},
+		{"not WAVE", append(append([]byte(nil), good[:8]...), append([]byte("AVI "), good[12:]...)...)},
+		{"no format", noChunk("fmt ")},
+		{"no data", noChunk("data")},
+		{"short format", append(append([]byte(nil), good[:16]...), append([]byte{8, 0, 0, 0}, good[20:28]...)...)},
+		{"no channels", makeWAV(wavPCM, 0, 11025, 16, nil)},
+		{"no rate", makeWAV(wavPCM, 1, 0, 16, nil)},
+		{"12 bit", makeWAV(wavPCM, 1, 11025, 12, nil)},
+		{"16 bit float", makeWAV(wavFloat, 1, 11025, 16, nil)},
+		{"ADPCM", makeWAV(0x0002, 1, 11025, 4, nil)},
+		{"extensible ADPCM", extensible(makeWAV(0x0002, 1, 11025, 4, nil))},
+	}
+	for _, tt := range tests {
+		if _, _, err := decodeWAV(tt.wav); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       []float64
+		from, to int
+		want     []float64
+	}{
+		{"same rate", []float64{0.1, 0.2}, 11025, 11025, []float64{0.1, 0.2}},
+		{"up", []float64{0, 1, 0}, 11025, 22050, []float64{0, 0.5, 1, 0.5, 0, 0}},
+		{"down", []float64{0, 1, 0.5, 0.5, -1, -1}, 22050, 11025, []float64{0.5, 0.5, -1}},
+		{"empty", nil, 44100, 11025, nil},
+	}
+	for _, tt := range tests {
+		got := resample(tt.in, tt.from, tt.to)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: %d samples, want %d", tt.name, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+				t.Errorf("%s: sample %d = %g, want %g", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package video
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Filter is a post-processing step applied to the decoded screen.
+	//
+	// Apply returns the filtered image. It may modify src in place, and the
+	// returned image may be reused by the next call.
+	//
+	// Bounds returns the bounds of the image that Apply returns for a source
+	// image with the given bounds.
+	Filter interface {
+		Apply(src *image.RGBA) *image.RGBA
+		Bounds(r image.Rectangle) image.Rectangle
+	}
+
+	// Pipeline is a chain of filters, applied in order.
+	Pipeline []Filter
+
+	// scale2x enlarges the image 2x with the Scale2x (EPX) algorithm, which
+	// smooths diagonal edges without blurring.
+	scale2x struct {
+		dst *image.RGBA
+	}
+
+	// scale3x enlarges the image 3x with the Scale3x algorithm.
+	scale3x struct {
+		dst *image.RGBA
+	}
+
+	// nearest scales the image with nearest neighbour sampling.
+	nearest struct {
+		f   float64
+		dst *image.RGBA
+	}
+
+	// bilinear scales the image with bilinear interpolation.
+	bilinear struct {
+		f   float64
+		dst *image.RGBA
+	}
+
+	// scanlines darkens every other row of the image, like the gaps between
+	// the scanlines of a CRT.
+	scanlines struct {
+		intensity float64
+	}
+
+	// phosphor blends each frame with the fading previous frames, like the
+	// persistence of CRT phosphor. This smooths the flicker of objects that
+	// are only drawn on alternate frames, such as the alien shots.
+	phosphor struct {
+		persistence float64
+		prev        []uint8
+	}
+)
+
+// ParseFilters returns the pipeline described by spec, a comma separated list
+// of filters with optional parameters:
+//
+//	scale2x               Scale2x (EPX) 2x enlargement
+//	scale3x               Scale3x 3x enlargement
+//	nearest:FACTOR        Nearest neighbour scaling (default 2)
+//	bilinear:FACTOR       Bilinear scaling (default 2)
+//	scanlines:INTENSITY   Darken alternate rows, 0 to 1 (default 0.5)
+//	phosphor:PERSISTENCE  Blend with the fading previous frames, 0 to 0.99
+//	                      (default 0.5)
+//
+// For example "phosphor:0.6,scale2x,bilinear:1.5,scanlines". An empty spec
+// returns an empty pipeline, which leaves the screen unchanged.
+func ParseFilters(spec string) (Pipeline, error) {
+	var p Pipeline
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		name, arg := s, ""
+		if i := strings.IndexByte(s, ':'); i >= 0 {
+			name, arg = s[:i], s[i+1:]
+		}
+
+		f, err := parseFilter(strings.ToLower(name), arg)
+		if err != nil {
+			return nil, fmt.Errorf("filter %q: %w", s, err)
+		}
+		p = append(p, f)
+	}
+
+	return p, nil
+}
+
+// parseFilter returns the named filter with the given parameter, which may be
+// empty to use the default.
+func parseFilter(name, arg string) (Filter, error) {
+	param := func(def, min, max float64) (float64, error) {
+		if arg == "" {
+			return def, nil
+		}
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil || v < min || v > max {
+			return 0, fmt.Errorf("invalid parameter %q: must be from %g to %g", arg, min, max)
+		}
+		return v, nil
+	}
+
+	switch name {
+	case "scale2x":
+		return &scale2x{}, nil
+	case "scale3x":
+		return &scale3x{}, nil
+	case "nearest":
+		f, err := param(2, 0.25, 16)
+		return &nearest{f: f}, err
+	case "bilinear":
+		f, err := param(2, 0.25, 16)
+		return &bilinear{f: f}, err
+	case "scanlines":
+		i, err := param(0.5, 0, 1)
+		return &scanlines{intensity: i}, err
+	case "phosphor":
+		p, err := param(0.5, 0, 0.99)
+		return &phosphor{persistence: p}, err
+	}
+
+	return nil, fmt.Errorf("unknown filter %q", name)
+}
+
+// Apply applies every filter in the pipeline in turn.
+func (p Pipeline) Apply(src *image.RGBA) *image.RGBA {
+	for _, f := range p {
+		src = f.Apply(src)
+	}
+
+	return src
+}
+
+// Bounds returns the bounds of the image that Apply returns for a source image
+// with the given bounds.
+func (p Pipeline) Bounds(r image.Rectangle) image.Rectangle {
+	for _, f := range p {
+		r = f.Bounds(r)
+	}
+
+	return r
+}
+
+// Apply implements Filter.
+func (s *scale2x) Apply(src *image.RGBA) *image.RGBA {
+	s.dst = reuse(s.dst, s.Bounds(src.Rect))
+
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// The pixel, and its neighbours above, to the left, to the right
+			// and below.
+			p := at(src, x, y)
+			a, c := at(src, x, y-1), at(src, x-1, y)
+			b, d := at(src, x+1, y), at(src, x, y+1)
+
+			e0, e1, e2, e3 := p, p, p, p
+			if a != d && c != b {
+				if c == a {
+					e0 = a
+				}
+				if a == b {
+					e1 = b
+				}
+				if c == d {
+					e2 = c
+				}
+				if b == d {
+					e3 = d
+				}
+			}
+
+			set(s.dst, 2*x, 2*y, e0)
+			set(s.dst, 2*x+1, 2*y, e1)
+			set(s.dst, 2*x, 2*y+1, e2)
+			set(s.dst, 2*x+1, 2*y+1, e3)
+		}
+	}
+
+	return s.dst
+}
+
+// Bounds implements Filter.
+func (s *scale2x) Bounds(r image.Rectangle) image.Rectangle {
+	return image.Rect(0, 0, r.Dx()*2, r.Dy()*2)
+}
+
+// Apply implements Filter.
+func (s *scale3x) Apply(src *image.RGBA) *image.RGBA {
+	s.dst = reuse(s.dst, s.Bounds(src.Rect))
+
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// The pixel e and its neighbours:
+			//
+			// a b c
+			// d e f
+			// g h i
+			a, b, c := at(src, x-1, y-1), at(src, x, y-1), at(src, x+1, y-1)
+			d, e, f := at(src, x-1, y), at(src, x, y), at(src, x+1, y)
+			g, h, i := at(src, x-1, y+1), at(src, x, y+1), at(src, x+1, y+1)
+
+			out := [9]uint32{e, e, e, e, e, e, e, e, e}
+			if b != h && d != f {
+				if d == b {
+					out[0] = d
+				}
+				if (d == b && e != c) || (b == f && e != a) {
+					out[1] = b
+				}
+				if b == f {
+					out[2] = f
+				}
+				if (d == b && e != g) || (d == h && e != a) {
+					out[3] = d
+				}
+				if (b == f && e != i) || (h == f && e != c) {
+					out[5] = f
+				}
+				if d == h {
+					out[6] = d
+				}
+				if (d == h && e != i) || (h == f && e != g) {
+					out[7] = h
+				}
+				if h == f {
+					out[8] = f
+				}
+			}
+
+			for n, v := range out {
+				set(s.dst, 3*x+n%3, 3*y+n/3, v)
+			}
+		}
+	}
+
+	return s.dst
+}
+
+// Bounds implements Filter.
+func (s *scale3x) Bounds(r image.Rectangle) image.Rectangle {
+	return image.Rect(0, 0, r.Dx()*3, r.Dy()*3)
+}
+
+// Apply implements Filter.
+func (n *nearest) Apply(src *image.RGBA) *image.RGBA {
+	n.dst = reuse(n.dst, n.Bounds(src.Rect))
+
+	w, h := n.dst.Rect.Dx(), n.dst.Rect.Dy()
+	for y := 0; y < h; y++ {
+		sy := int(float64(y) / n.f)
+		for x := 0; x < w; x++ {
+			set(n.dst, x, y, at(src, int(float64(x)/n.f), sy))
+		}
+	}
+
+	return n.dst
+}
+
+// Bounds implements Filter.
+func (n *nearest) Bounds(r image.Rectangle) image.Rectangle {
+	return scaled(r, n.f)
+}
+
+// Apply implements Filter.
+func (b *bilinear) Apply(src *image.RGBA) *image.RGBA {
+	b.dst = reuse(b.dst, b.Bounds(src.Rect))
+
+	w, h := b.dst.Rect.Dx(), b.dst.Rect.Dy()
+	for y := 0; y < h; y++ {
+		// Sample at the centre of the destination pixel.
+		sy := (float64(y)+0.5)/b.f - 0.5
+		y0 := int(math.Floor(sy))
+		fy := sy - float64(y0)
+
+		for x := 0; x < w; x++ {
+			sx := (float64(x)+0.5)/b.f - 0.5
+			x0 := int(math.Floor(sx))
+			fx := sx - float64(x0)
+
+			p00, p10 := src.PixOffset(clamp(src, x0, y0)), src.PixOffset(clamp(src, x0+1, y0))
+			p01, p11 := src.PixOffset(clamp(src, x0, y0+1)), src.PixOffset(clamp(src, x0+1, y0+1))
+
+			d := b.dst.PixOffset(x, y)
+			for ch := 0; ch < 4; ch++ {
+				top := float64(src.Pix[p00+ch])*(1-fx) + float64(src.Pix[p10+ch])*fx
+				bot := float64(src.Pix[p01+ch])*(1-fx) + float64(src.Pix[p11+ch])*fx
+				b.dst.Pix[d+ch] = uint8(top*(1-fy) + bot*fy + 0.5)
+			}
+		}
+	}
+
+	return b.dst
+}
+
+// Bounds implements Filter.
+func (b *bilinear) Bounds(r image.Rectangle) image.Rectangle {
+	return scaled(r, b.f)
+}
+
+// Apply implements Filter.
+func (s *scanlines) Apply(src *image.RGBA) *image.RGBA {
+	keep := uint32((1 - s.intensity) * 256)
+	for y := src.Rect.Min.Y + 1; y < src.Rect.Max.Y; y += 2 {
+		row := src.Pix[src.PixOffset(src.Rect.Min.X, y):src.PixOffset(src.Rect.Max.X, y)]
+		for i := 0; i < len(row); i += 4 {
+			row[i+0] = uint8(uint32(row[i+0]) * keep >> 8)
+			row[i+1] = uint8(uint32(row[i+1]) * keep >> 8)
+			row[i+2] = uint8(uint32(row[i+2]) * keep >> 8)
+		}
+	}
+
+	return src
+}
+
+// Bounds implements Filter.
+func (s *scanlines) Bounds(r image.Rectangle) image.Rectangle {
+	return r
+}
+
+// Apply implements Filter.
+func (p *phosphor) Apply(src *image.RGBA) *image.RGBA {
+	if len(p.prev) != len(src.Pix) {
+		p.prev = make([]uint8, len(src.Pix))
+	}
+
+	keep := uint32(p.persistence * 256)
+	for i, v := range src.Pix {
+		// Each pixel glows at the brighter of its new value and the faded
+		// glow of the previous frames.
+		if faded := uint8(uint32(p.prev[i]) * keep >> 8); faded > v {
+			src.Pix[i] = faded
+		}
+	}
+	copy(p.prev, src.Pix)
+
+	return src
+}
+
+// Bounds implements Filter.
+func (p *phosphor) Bounds(r image.Rectangle) image.Rectangle {
+	return r
+}
+
+// reuse returns dst if it has the given bounds, or a new image otherwise.
+func reuse(dst *image.RGBA, r image.Rectangle) *image.RGBA {
+	if dst != nil && dst.Rect == r {
+		return dst
+	}
+
+	return image.NewRGBA(r)
+}
+
+// scaled returns bounds at the origin scaled by the factor f.
+func scaled(r image.Rectangle, f float64) image.Rectangle {
+	return image.Rect(0, 0, int(math.Round(float64(r.Dx())*f)), int(math.Round(float64(r.Dy())*f)))
+}
+
+// clamp returns the co-ordinates relative to the origin of src, clamped to lie
+// within it.
+func clamp(src *image.RGBA, x, y int) (int, int) {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	if x < 0 {
+		x = 0
+	} else if x >= w {
+		x = w - 1
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= h {
+		y = h - 1
+	}
+
+	return src.Rect.Min.X + x, src.Rect.Min.Y + y
+}
+
+// at returns the packed RGBA value of the pixel at the co-ordinates relative
+// to the origin of src, clamped to lie within it.
+func at(src *image.RGBA, x, y int) uint32 {
+	p := src.PixOffset(clamp(src, x, y))
+	return uint32(src.Pix[p])<<24 | uint32(src.Pix[p+1])<<16 | uint32(src.Pix[p+2])<<8 | uint32(src.Pix[p+3])
+}
+
+// set sets the pixel of dst, which has its origin at 0,0, to a packed RGBA
+// value.
+func set(dst *image.RGBA, x, y int, v uint32) {
+	p := dst.PixOffset(x, y)
+	dst.Pix[p+0] = uint8(v >> 24)
+	dst.Pix[p+1] = uint8(v >> 16)
+	dst.Pix[p+2] = uint8(v >> 8)
+	dst.Pix[p+3] = uint8(v)
+}
@@ -0,0 +1,310 @@
+package video
+
+import (
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// diagonal is an 8x8 test image of a line from the top left to the bottom
+// right, in the form taken by imageFromRows.
+var diagonal = []string{
+	"#.......",
+	".#......",
+	"..#.....",
+	"...#....",
+	"....#...",
+	".....#..",
+	"......#.",
+	".......#",
+}
+
+// imageFromRows returns an image drawn from rows of text, where '#' is a white
+// pixel and any other character a black pixel.
+func imageFromRows(rows []string) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, c := range row {
+			var v uint32 = 0x000000ff
+			if c == '#' {
+				v = 0xffffffff
+			}
+			set(img, x, y, v)
+		}
+	}
+
+	return img
+}
+
+// rowsFromImage returns rows of text drawn from an image, where '#' is a white
+// pixel, '.' a black pixel and '?' any other colour.
+func rowsFromImage(img *image.RGBA) []string {
+	rows := make([]string, img.Rect.Dy())
+	for y := range rows {
+		var b strings.Builder
+		for x := 0; x < img.Rect.Dx(); x++ {
+			switch at(img, x, y) {
+			case 0xffffffff:
+				b.WriteByte('#')
+			case 0x000000ff:
+				b.WriteByte('.')
+			default:
+				b.WriteByte('?')
+			}
+		}
+		rows[y] = b.String()
+	}
+
+	return rows
+}
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Pipeline
+	}{
+		{"", nil},
+		{" , ", nil},
+		{"scale2x", Pipeline{&scale2x{}}},
+		{"Scale3x", Pipeline{&scale3x{}}},
+		{"nearest", Pipeline{&nearest{f: 2}}},
+		{"nearest:0.5", Pipeline{&nearest{f: 0.5}}},
+		{"bilinear:1.5", Pipeline{&bilinear{f: 1.5}}},
+		{"scanlines", Pipeline{&scanlines{intensity: 0.5}}},
+		{"scanlines:1", Pipeline{&scanlines{intensity: 1}}},
+		{"phosphor:0.99", Pipeline{&phosphor{persistence: 0.99}}},
+		{
+			"phosphor:0.6, scale2x,bilinear:1.5 ,scanlines",
+			Pipeline{&phosphor{persistence: 0.6}, &scale2x{}, &bilinear{f: 1.5}, &scanlines{intensity: 0.5}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := ParseFilters(tt.spec)
+		if err != nil {
+			t.Errorf("ParseFilters(%q): %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFilters(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseFiltersInvalid(t *testing.T) {
+	tests := []string{
+		"nearest:0",
+		"nearest:17",
+		"nearest:two",
+		"bilinear:0.1",
+		"scanlines:-0.5",
+		"scanlines:1.5",
+		"phosphor:1",
+		"blur",
+		"scale2x,blur:2",
+	}
+	for _, spec := range tests {
+		if _, err := ParseFilters(spec); err == nil {
+			t.Errorf("ParseFilters(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestScaleFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Filter
+		want []string
+	}{
+		{
+			// The staircase is filled in to a smooth line, apart from the
+			// corners at either end, where the edge of the image is taken to
+			// continue the pixels on it.
+			name: "scale2x",
+			f:    &scale2x{},
+			want: []string{
+				"##..............",
+				"#.#.............",
+				".###............",
+				"..###...........",
+				"...###..........",
+				"....###.........",
+				".....###........",
+				"......###.......",
+				".......###......",
+				"........###.....",
+				".........###....",
+				"..........###...",
+				"...........###..",
+				"............###.",
+				".............#.#",
+				"..............##",
+			},
+		},
+		{
+			name: "scale3x",
+			f:    &scale3x{},
+			want: []string{
+				"###.....................",
+				"##.#....................",
+				"#..#....................",
+				".#####..................",
+				"...###..................",
+				"...####.................",
+				".....####...............",
+				"......###...............",
+				"......####..............",
+				"........####............",
+				".........###............",
+				".........####...........",
+				"...........####.........",
+				"............###.........",
+				"............####........",
+				"..............####......",
+				"...............###......",
+				"...............####.....",
+				".................####...",
+				"..................###...",
+				"..................#####.",
+				"....................#..#",
+				"....................#.##",
+				".....................###",
+			},
+		},
+		{
+			name: "nearest 2",
+			f:    &nearest{f: 2},
+			want: []string{
+				"##..............",
+				"##..............",
+				"..##............",
+				"..##............",
+				"....##..........",
+				"....##..........",
+				"......##........",
+				"......##........",
+				"........##......",
+				"........##......",
+				"..........##....",
+				"..........##....",
+				"............##..",
+				"............##..",
+				"..............##",
+				"..............##",
+			},
+		},
+		{
+			name: "nearest 1.5",
+			f:    &nearest{f: 1.5},
+			want: []string{
+				"##..........",
+				"##..........",
+				"..#.........",
+				"...##.......",
+				"...##.......",
+				".....#......",
+				"......##....",
+				"......##....",
+				"........#...",
+				".........##.",
+				".........##.",
+				"...........#",
+			},
+		},
+		{
+			name: "nearest 0.5",
+			f:    &nearest{f: 0.5},
+			want: []string{
+				"#...",
+				".#..",
+				"..#.",
+				"...#",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if b := tt.f.Bounds(image.Rect(0, 0, 8, 8)); b.Dx() != len(tt.want[0]) || b.Dy() != len(tt.want) {
+				t.Errorf("bounds = %v, want %dx%d", b, len(tt.want[0]), len(tt.want))
+			}
+
+			// Apply twice, as the output image is reused.
+			for i := 0; i < 2; i++ {
+				got := rowsFromImage(tt.f.Apply(imageFromRows(diagonal)))
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+				}
+			}
+		})
+	}
+}
+
+func TestBilinear(t *testing.T) {
+	src := imageFromRows([]string{".#"})
+	got := (&bilinear{f: 2}).Apply(src)
+
+	// Each destination pixel is sampled at its centre, so the step from
+	// black to white is spread over the middle two pixels.
+	want := []uint8{0x00, 0x40, 0xbf, 0xff}
+	if got.Rect != image.Rect(0, 0, 4, 2) {
+		t.Fatalf("bounds = %v, want 4x2", got.Rect)
+	}
+	for y := 0; y < 2; y++ {
+		for x, v := range want {
+			if p := at(got, x, y); p != uint32(v)<<24|uint32(v)<<16|uint32(v)<<8|0xff {
+				t.Errorf("pixel %d,%d = %08x, want grey %02x", x, y, p, v)
+			}
+		}
+	}
+}
+
+func TestScanlines(t *testing.T) {
+	tests := []struct {
+		intensity float64
+		dark      uint8
+	}{
+		{0, 0xff},
+		{0.25, 0xbf},
+		{0.5, 0x7f},
+		{1, 0x00},
+	}
+	for _, tt := range tests {
+		got := (&scanlines{intensity: tt.intensity}).Apply(imageFromRows([]string{"##", "##", "##", "##"}))
+
+		for y := 0; y < 4; y++ {
+			v := uint8(0xff)
+			if y%2 == 1 {
+				v = tt.dark
+			}
+			for x := 0; x < 2; x++ {
+				if p := at(got, x, y); p != uint32(v)<<24|uint32(v)<<16|uint32(v)<<8|0xff {
+					t.Errorf("intensity %g: pixel %d,%d = %08x, want grey %02x", tt.intensity, x, y, p, v)
+				}
+			}
+		}
+	}
+}
+
+func TestPhosphor(t *testing.T) {
+	p := &phosphor{persistence: 0.5}
+
+	// A pixel lit for one frame fades by half each frame after, while a lit
+	// pixel stays lit.
+	frames := []struct {
+		src  []string
+		want []uint8
+	}{
+		{[]string{"#."}, []uint8{0xff, 0x00}},
+		{[]string{".#"}, []uint8{0x7f, 0xff}},
+		{[]string{".."}, []uint8{0x3f, 0x7f}},
+		{[]string{".."}, []uint8{0x1f, 0x3f}},
+	}
+	for i, f := range frames {
+		got := p.Apply(imageFromRows(f.src))
+		for x, v := range f.want {
+			if px := at(got, x, 0); px != uint32(v)<<24|uint32(v)<<16|uint32(v)<<8|0xff {
+				t.Errorf("frame %d: pixel %d = %08x, want grey %02x", i, x, px, v)
+			}
+		}
+	}
+}
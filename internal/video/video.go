@@ -0,0 +1,87 @@
+// Package video converts the Space Invaders video RAM into images that any
+// frontend can display.
+package video
+
+import (
+	"image"
+
+	"github.com/danmrichards/go-invaders/internal/overlay"
+)
+
+const (
+	// Width and Height are the dimensions of the upright screen.
+	Width, Height = 224, 256
+
+	// VRAMSize is the size of the video RAM, at 1 bit per pixel.
+	VRAMSize = Width * Height / 8
+)
+
+// NewImage returns an image the size of the upright screen.
+func NewImage() *image.RGBA {
+	return image.NewRGBA(image.Rect(0, 0, Width, Height))
+}
+
+// Decode decodes the 1bpp video RAM into dst, which must be the size of the
+// upright screen.
+//
+// The monitor is mounted on its side, so each byte of video RAM holds 8
+// vertically adjacent pixels of a column, starting at the bottom left of the
+// screen. Lit pixels take the colour of the overlay, or white if the overlay is
+// nil, and unlit pixels are black.
+func Decode(dst *image.RGBA, vram []byte, o *overlay.Overlay) {
+	// Clear the image to opaque black.
+	for i := 0; i < len(dst.Pix); i += 4 {
+		dst.Pix[i+0] = 0x00
+		dst.Pix[i+1] = 0x00
+		dst.Pix[i+2] = 0x00
+		dst.Pix[i+3] = 0xff
+	}
+
+	for i, vb := range vram[:VRAMSize] {
+		if vb == 0 {
+			continue
+		}
+
+		x := i / (Height / 8)
+		y := (i % (Height / 8)) * 8
+		for bit := 0; bit < 8; bit, y = bit+1, y+1 {
+			if (vb>>bit)&0x01 == 0x00 {
+				continue
+			}
+
+			// Screen co-ordinates have their origin at the bottom left,
+			// whereas image co-ordinates have their origin at the top left.
+			p := dst.PixOffset(x, Height-1-y)
+			if o == nil {
+				dst.Pix[p+0], dst.Pix[p+1], dst.Pix[p+2] = 0xff, 0xff, 0xff
+				continue
+			}
+
+			c := o.At(x, y)
+			dst.Pix[p+0], dst.Pix[p+1], dst.Pix[p+2] = c.R, c.G, c.B
+		}
+	}
+}
+
+// FlipRows copies the pixels of src into dst, bottom row first, as expected by
+// OpenGL textures. dst must be 4*width*height bytes.
+func FlipRows(dst []uint8, src *image.RGBA) {
+	w := src.Rect.Dx() * 4
+	h := src.Rect.Dy()
+	for y := 0; y < h; y++ {
+		copy(dst[(h-1-y)*w:(h-y)*w], src.Pix[src.PixOffset(src.Rect.Min.X, src.Rect.Min.Y+y):])
+	}
+}
+
+// Screenshot returns an image of the screen decoded from vram, through the
+// overlay if it is not nil, and enlarged by the given integer scale factor
+// with nearest neighbour sampling.
+func Screenshot(vram []byte, o *overlay.Overlay, scale int) *image.RGBA {
+	img := NewImage()
+	Decode(img, vram, o)
+	if scale <= 1 {
+		return img
+	}
+
+	return (&nearest{f: float64(scale)}).Apply(img)
+}
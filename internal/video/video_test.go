@@ -0,0 +1,92 @@
+package video
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/danmrichards/go-invaders/internal/overlay"
+)
+
+// benchScreens are synthetic video RAM contents to benchmark rendering with:
+// a screen with about an eighth of the pixels lit, roughly as many as in play,
+// and a fully lit screen, the worst case.
+var benchScreens = []struct {
+	name string
+	vram []byte
+}{
+	{"typical", typicalVRAM()},
+	{"lit", litVRAM()},
+}
+
+// typicalVRAM returns video RAM with about an eighth of the pixels lit.
+func typicalVRAM() []byte {
+	r := rand.New(rand.NewSource(1))
+
+	vram := make([]byte, VRAMSize)
+	for i := range vram {
+		vram[i] = byte(1 << uint(r.Intn(8)))
+	}
+
+	return vram
+}
+
+// litVRAM returns video RAM with every pixel lit.
+func litVRAM() []byte {
+	vram := make([]byte, VRAMSize)
+	for i := range vram {
+		vram[i] = 0xff
+	}
+
+	return vram
+}
+
+// benchOverlay returns the overlay to benchmark rendering with.
+func benchOverlay(b *testing.B) *overlay.Overlay {
+	b.Helper()
+
+	o, err := overlay.Load("upright")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return o
+}
+
+// BenchmarkDecode benchmarks decoding the video RAM into an image.
+func BenchmarkDecode(b *testing.B) {
+	o := benchOverlay(b)
+
+	for _, s := range benchScreens {
+		b.Run(s.name, func(b *testing.B) {
+			img := NewImage()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Decode(img, s.vram, o)
+			}
+		})
+	}
+}
+
+// BenchmarkRender benchmarks the CPU side of rendering a frame: decoding the
+// video RAM, running the default filters and flipping the rows into a texture.
+// The cost of uploading the texture to the GPU is not included.
+func BenchmarkRender(b *testing.B) {
+	o := benchOverlay(b)
+	p, err := ParseFilters("nearest:2")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, s := range benchScreens {
+		b.Run(s.name, func(b *testing.B) {
+			img := NewImage()
+			r := p.Bounds(img.Rect)
+			pix := make([]uint8, r.Dx()*r.Dy()*4)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Decode(img, s.vram, o)
+				FlipRows(pix, p.Apply(img))
+			}
+		})
+	}
+}
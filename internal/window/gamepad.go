@@ -0,0 +1,84 @@
+package window
+
+import (
+	"github.com/danmrichards/go-invaders/internal/config"
+	"github.com/danmrichards/go-invaders/internal/machine"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// playerButtons are the cabinet inputs of each player, player 1 first.
+var playerButtons = []struct {
+	left, right, shoot, start machine.Button
+}{
+	{machine.ButtonP1Left, machine.ButtonP1Right, machine.ButtonP1Shoot, machine.ButtonP1Start},
+	{machine.ButtonP2Left, machine.ButtonP2Right, machine.ButtonP2Shoot, machine.ButtonP2Start},
+}
+
+// WithGamepads sets the gamepad of each player, player 1 first.
+func WithGamepads(gps []config.Gamepad) Option {
+	return func(w *Window) {
+		w.gps = gps
+	}
+}
+
+// gamepadPressed returns true if any gamepad control bound to the given button
+// is pressed.
+func (w *Window) gamepadPressed(b machine.Button) bool {
+	for i, gp := range w.gps {
+		if i >= len(playerButtons) || gp.Joystick == 0 {
+			continue
+		}
+
+		js := pixelgl.Joystick1 + pixelgl.Joystick(gp.Joystick-1)
+		if !w.w.JoystickPresent(js) {
+			continue
+		}
+
+		var pressed bool
+		switch pb := playerButtons[i]; b {
+		case pb.left:
+			pressed = w.axis(js, gp) < -gp.DeadZone || w.joystickPressed(js, gp.Left)
+		case pb.right:
+			pressed = w.axis(js, gp) > gp.DeadZone || w.joystickPressed(js, gp.Right)
+		case pb.shoot:
+			pressed = w.joystickPressed(js, gp.Shoot)
+		case pb.start:
+			pressed = w.joystickPressed(js, gp.Start)
+		case machine.ButtonCoin:
+			pressed = w.joystickPressed(js, gp.Coin)
+		}
+		if pressed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// axis returns the position of the horizontal axis of the gamepad, from -1
+// (left) to 1 (right).
+//
+// If the gamepad has several horizontal axes, such as an analog stick and a
+// d-pad, the one furthest from the centre is returned.
+func (w *Window) axis(js pixelgl.Joystick, gp config.Gamepad) float64 {
+	var v float64
+	for _, a := range gp.Axes {
+		if av := w.w.JoystickAxis(js, a); av*av > v*v {
+			v = av
+		}
+	}
+
+	return v
+}
+
+// joystickPressed returns true if any of the given joystick buttons is
+// pressed.
+func (w *Window) joystickPressed(js pixelgl.Joystick, buttons []int) bool {
+	for _, b := range buttons {
+		if w.w.JoystickPressed(js, b) {
+			return true
+		}
+	}
+
+	return false
+}
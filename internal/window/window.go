@@ -0,0 +1,126 @@
+package window
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/danmrichards/go-invaders/internal/config"
+	"github.com/danmrichards/go-invaders/internal/keymap"
+	"github.com/danmrichards/go-invaders/internal/machine"
+	"github.com/danmrichards/go-invaders/internal/overlay"
+	"github.com/danmrichards/go-invaders/internal/video"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+type (
+	// Window is a pixelgl implementation of the machine video and input
+	// frontends.
+	Window struct {
+		// The render window.
+		w *pixelgl.Window
+
+		// The post-processing filters, which also set the size of the
+		// window.
+		f video.Pipeline
+
+		// The key bindings.
+		b keymap.Bindings
+
+		// The gamepad of each player.
+		gps []config.Gamepad
+
+		// The colour overlay.
+		o *overlay.Overlay
+
+		// The screen is decoded into an image and filtered, then uploaded
+		// to the canvas texture bottom row first and drawn as a single
+		// sprite.
+		img *image.RGBA
+		pix []uint8
+		c   *pixelgl.Canvas
+	}
+
+	// Option is a functional option that modifies a field on the window.
+	Option func(*Window)
+)
+
+// WithFilters sets the post-processing filters applied to the screen.
+func WithFilters(f video.Pipeline) Option {
+	return func(w *Window) {
+		w.f = f
+	}
+}
+
+// WithBindings sets the key bindings.
+func WithBindings(b keymap.Bindings) Option {
+	return func(w *Window) {
+		w.b = b
+	}
+}
+
+// WithOverlay sets the colour overlay.
+func WithOverlay(o *overlay.Overlay) Option {
+	return func(w *Window) {
+		w.o = o
+	}
+}
+
+// New returns an instantiated window.
+//
+// New must be called from the function passed to pixelgl.Run.
+func New(opts ...Option) (w *Window, err error) {
+	w = &Window{
+		b:   keymap.Default(),
+		img: video.NewImage(),
+	}
+
+	for _, o := range opts {
+		o(w)
+	}
+
+	r := w.f.Bounds(w.img.Rect)
+	w.pix = make([]uint8, r.Dx()*r.Dy()*4)
+
+	w.w, err = pixelgl.NewWindow(pixelgl.WindowConfig{
+		Title:  "Space Invaders",
+		Bounds: pixel.R(0, 0, float64(r.Dx()), float64(r.Dy())),
+		VSync:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.c = pixelgl.NewCanvas(pixel.R(0, 0, float64(r.Dx()), float64(r.Dy())))
+
+	return w, nil
+}
+
+// Render renders the given screen to the window.
+func (w *Window) Render(s machine.Screen) {
+	// Decode and filter the screen, then upload it to the canvas texture.
+	video.Decode(w.img, s.VRAM(), w.o)
+	video.FlipRows(w.pix, w.f.Apply(w.img))
+	w.c.SetPixels(w.pix)
+
+	// Draw the canvas to fill the window.
+	w.w.Clear(color.Black)
+	w.c.Draw(w.w, pixel.IM.Moved(w.w.Bounds().Center()))
+	w.w.Update()
+}
+
+// Closed returns true if the window has been closed.
+func (w *Window) Closed() bool {
+	return w.w.Closed()
+}
+
+// Pressed returns true if any key or gamepad control bound to the given button
+// is pressed.
+func (w *Window) Pressed(b machine.Button) bool {
+	for _, k := range w.b[b] {
+		if w.w.Pressed(pixelgl.Button(k)) {
+			return true
+		}
+	}
+
+	return w.gamepadPressed(b)
+}
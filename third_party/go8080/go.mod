@@ -0,0 +1,5 @@
+module github.com/danmrichards/go8080
+
+go 1.14
+
+require github.com/danmrichards/disassemble8080 v1.1.0
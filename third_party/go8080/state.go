@@ -0,0 +1,63 @@
+package go8080
+
+// State represents a snapshot of the registers, condition bits and status of
+// the Intel 8080.
+//
+// It allows a machine to save and restore the CPU, for example to implement
+// save states or rewinding.
+type State struct {
+	// Registers, indexed by the named working registers.
+	R [8]byte
+
+	// Condition bits in the form of the "Program Status Word" status byte.
+	Status byte
+
+	// Stack pointer.
+	SP uint16
+
+	// Program counter.
+	PC uint16
+
+	// Interrupts enabled.
+	IE bool
+
+	// Has the CPU been halted?
+	Halted bool
+
+	// Count of CPU cycles.
+	Cycles uint32
+}
+
+// State returns a snapshot of the current state of the CPU.
+func (i *Intel8080) State() State {
+	return State{
+		R:      i.r,
+		Status: i.cc.status(),
+		SP:     i.sp,
+		PC:     i.pc,
+		IE:     i.ie,
+		Halted: i.halted,
+		Cycles: i.cyc,
+	}
+}
+
+// SetState restores the state of the CPU from the given snapshot.
+func (i *Intel8080) SetState(s State) {
+	i.r = s.R
+	i.cc.setStatus(s.Status)
+	i.sp = s.SP
+	i.pc = s.PC
+	i.ie = s.IE
+	i.halted = s.Halted
+	i.cyc = s.Cycles
+}
+
+// PC returns the current value of the program counter.
+func (i *Intel8080) PC() uint16 {
+	return i.pc
+}
+
+// InterruptsEnabled returns true if the CPU will accept an interrupt.
+func (i *Intel8080) InterruptsEnabled() bool {
+	return i.ie
+}
@@ -0,0 +1,28 @@
+package go8080
+
+import "testing"
+
+func TestState(t *testing.T) {
+	want := State{
+		R:      [8]byte{B: 0x01, C: 0x02, D: 0x03, E: 0x04, H: 0x05, L: 0x06, A: 0x07},
+		Status: 0xd7,
+		SP:     0x2400,
+		PC:     0x1234,
+		IE:     true,
+		Halted: true,
+		Cycles: 123456,
+	}
+
+	i := NewIntel8080(make(mem, 65536))
+	i.SetState(want)
+
+	if got := i.State(); got != want {
+		t.Errorf("state = %+v, want %+v", got, want)
+	}
+	if i.PC() != want.PC {
+		t.Errorf("PC = %04x, want %04x", i.PC(), want.PC)
+	}
+	if !i.InterruptsEnabled() {
+		t.Error("interrupts not enabled")
+	}
+}
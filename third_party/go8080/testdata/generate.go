@@ -0,0 +1,6 @@
+package testdata
+
+//go:generate curl -LO http://altairclone.com/downloads/cpu_tests/8080EXM.COM
+//go:generate curl -LO http://altairclone.com/downloads/cpu_tests/8080PRE.COM
+//go:generate curl -LO http://altairclone.com/downloads/cpu_tests/CPUTEST.COM
+//go:generate curl -LO http://altairclone.com/downloads/cpu_tests/TST8080.COM